@@ -1,13 +1,14 @@
 package cli
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/rodaine/table"
@@ -27,6 +28,7 @@ func New() *cli.App {
 		Name:  "manifestus",
 		Usage: "Render Kubernetes manifests from a declarative configuration",
 		Commands: []*cli.Command{
+			validateCommand,
 			appsCommand,
 			typesCommand,
 			chartsCommand,
@@ -34,11 +36,40 @@ func New() *cli.App {
 			renderCommand,
 			writeCommand,
 			checkCommand,
+			diffCommand,
+			lintCommand,
+			validateManifestsCommand,
+			cacheCommand,
 			versionCommand,
 		},
 	}
 }
 
+var validateCommand = &cli.Command{
+	Name:  "validate",
+	Usage: "Validate the Renderfile.\n\nExit with status code 1 if problems are found.",
+	Flags: []cli.Flag{
+		&renderfileFlag,
+		&quietFlag,
+	},
+	Action: func(c *cli.Context) error {
+		// Load the config file from disk, which validates it before decoding it.
+		_, err := core.LoadConfig(flags.RenderFile)
+
+		// If there are problems, show each on its own line and exit with a non-zero exit code to indicate problems found.
+		var problems core.ValidationErrors
+		if errors.As(err, &problems) {
+			for _, problem := range problems {
+				fmt.Println(problem)
+			}
+			os.Exit(1)
+		}
+		exitOnError(err, -1)
+		printMsg(fmt.Sprintf("Renderfile %s is valid", flags.RenderFile), false)
+		return nil
+	},
+}
+
 var appsCommand = &cli.Command{
 	Name:  "apps",
 	Usage: "Show list of all apps",
@@ -61,10 +92,10 @@ var appsCommand = &cli.Command{
 
 var typesCommand = &cli.Command{
 	Name:  "types",
-	Usage: "Show table of all source types names and descriptions",
+	Usage: "Show table of all registered source types and their capabilities",
 	Action: func(c *cli.Context) error {
-		// Print the names of all source types in the config to stdout.
-		table, err := getTypesTable(core.ValidSrcTypes)
+		// Print the source types of all registered renderers to stdout.
+		table, err := getTypesTable(core.Renderers())
 		exitOnError(err, -1)
 		table.Print()
 		return nil
@@ -114,35 +145,48 @@ var outputsCommand = &cli.Command{
 	Usage: "Show list of output files of rendered manifests",
 	Flags: []cli.Flag{
 		&renderfileFlag,
+		&outputDirFlag,
 		&appNamesFlag,
 		&srcNamesFlag,
 		&srcTypesFlag,
 		&flattenFlag,
+		&layoutFlag,
+		&envFlag,
+		&allEnvsFlag,
 	},
 	Action: func(c *cli.Context) error {
 		// Load the config file from disk.
 		cfg, err := core.LoadConfig(flags.RenderFile)
 		exitOnError(err, -1)
 
-		// Get the app names to target.
-		appNames, err := getAppNames(cfg, flags.AppNames.Value())
-		if err != nil {
-			exitOnError(err, -1)
-		}
+		// Get the environments to target.
+		targets, err := getEnvTargets(cfg)
+		exitOnError(err, -1)
 
 		// Ensure that we have src types and they are valid.
 		srcTypes := flags.SrcTypes.Value()
 		if len(srcTypes) == 0 {
-			srcTypes = core.StringKeys(core.ValidSrcTypes)
+			srcTypes = core.SrcTypes()
 		} else {
 			err = core.EnsureSrcTypesValid(flags.SrcTypes.Value())
 			exitOnError(err, -1)
 		}
 
-		// Print the output files of the rendered manifests for the apps to stdout.
-		outputs := core.GetOutputFiles(cfg, appNames, flags.SrcNames.Value(), srcTypes, flags.Flatten)
-		for _, output := range outputs {
-			fmt.Println(output)
+		for _, target := range targets {
+			// Get the app names to target, which may be enabled by the environment.
+			appNames, err := getAppNames(target.Config, flags.AppNames.Value())
+			exitOnError(err, -1)
+
+			// Print the output files of the rendered manifests for the apps to stdout, prefixed with the output
+			// directory of their environment if targeting environments.
+			outputs, err := core.GetOutputFiles(target.Config, appNames, flags.SrcNames.Value(), srcTypes, getOutputOptions(target.Config))
+			exitOnError(err, -1)
+			for _, output := range outputs {
+				if target.Name != "" {
+					output = path.Join(target.OutputDir, output)
+				}
+				fmt.Println(output)
+			}
 		}
 		return nil
 	},
@@ -158,49 +202,78 @@ var renderCommand = &cli.Command{
 		&srcTypesFlag,
 		&dryRunFlag,
 		&debugFlag,
+		&jobsFlag,
+		&noCacheFlag,
 		&noBannerFlag,
+		&showFilteredFlag,
+		&envFlag,
+		&allEnvsFlag,
 	},
 	Action: func(c *cli.Context) error {
 		// Load the config file from disk.
 		cfg, err := core.LoadConfig(flags.RenderFile)
 		exitOnError(err, -1)
 
-		// Get the app names to target.
-		appNames, err := getAppNames(cfg, flags.AppNames.Value())
-		if err != nil {
-			exitOnError(err, -1)
-		}
+		// Get the environments to target.
+		targets, err := getEnvTargets(cfg)
+		exitOnError(err, -1)
 
 		// Ensure that we have src types and they are valid.
 		srcTypes := flags.SrcTypes.Value()
 		if len(srcTypes) == 0 {
-			srcTypes = core.StringKeys(core.ValidSrcTypes)
+			srcTypes = core.SrcTypes()
 		} else {
 			err = core.EnsureSrcTypesValid(flags.SrcTypes.Value())
 			exitOnError(err, -1)
 		}
 
-		// Get the renders for the apps and ensure that they are OK.
-		renders, err := core.GetRenders(cfg, appNames, flags.SrcNames.Value(), srcTypes, flags.Debug, flags.DryRun)
-		exitOnError(err, -1)
+		for _, target := range targets {
+			renderManifests(target.Config, srcTypes)
+		}
+		return nil
+	},
+}
 
-		// If dry-run is enabled, just print the command lines to stdout and return.
-		if flags.DryRun {
-			for _, render := range renders {
-				if render.CmdLine != "" { // Skip static manifests as they aren't rendered with a command line.
-					fmt.Println(render.CmdLine)
-				}
+// renderManifests renders the manifests of the targeted apps and sources of a config to stdout.
+func renderManifests(cfg *core.Config, srcTypes []string) {
+	// Get the app names to target.
+	appNames, err := getAppNames(cfg, flags.AppNames.Value())
+	exitOnError(err, -1)
+
+	// Get the renders for the apps and ensure that they are OK.
+	renders, err := core.GetRenders(cfg, appNames, flags.SrcNames.Value(), srcTypes, getRenderOptions())
+	exitOnError(err, -1)
+
+	// If dry-run is enabled, just print the command lines to stdout and return.
+	if flags.DryRun {
+		for _, render := range renders {
+			if render.CmdLine != "" { // Skip static manifests as they aren't rendered with a command line.
+				fmt.Println(render.CmdLine)
 			}
-			return nil
 		}
+		return
+	}
 
-		// Otherwise, print the rendered manifests to stdout and return.
-		manifests := core.GetManifests(renders)
-		for _, manifest := range manifests {
-			fmt.Println(manifest.Doc(flags.NoBanner))
+	// If show-filtered is enabled, print the objects removed by the filters of sources instead.
+	if flags.ShowFiltered {
+		filtered := make([]*core.Render, 0)
+		for _, render := range renders {
+			if len(render.Filtered) > 0 {
+				copied := *render
+				copied.Stdout = render.Filtered
+				filtered = append(filtered, &copied)
+			}
 		}
-		return nil
-	},
+		renders = filtered
+	}
+
+	// Print the rendered manifests to stdout.
+	manifests := core.GetManifests(renders)
+	for _, manifest := range manifests {
+		doc, err := manifest.NormalizedDoc(getOutputOptions(cfg))
+		exitOnError(err, -1)
+		fmt.Println(strings.TrimSuffix(doc, "\n"))
+	}
 }
 
 var writeCommand = &cli.Command{
@@ -214,56 +287,106 @@ var writeCommand = &cli.Command{
 		&srcTypesFlag,
 		&cleanFlag,
 		&debugFlag,
+		&jobsFlag,
+		&noCacheFlag,
 		&verboseFlag,
 		&flattenFlag,
+		&layoutFlag,
 		&noBannerFlag,
+		&kustomizeIndexFlag,
+		&envFlag,
+		&allEnvsFlag,
 	},
 	Action: func(c *cli.Context) error {
 		// Load the config file from disk.
 		cfg, err := core.LoadConfig(flags.RenderFile)
 		exitOnError(err, -1)
 
-		// Get the app names to target.
-		appNames, err := getAppNames(cfg, flags.AppNames.Value())
-		if err != nil {
-			exitOnError(err, -1)
-		}
+		// Get the environments to target.
+		targets, err := getEnvTargets(cfg)
+		exitOnError(err, -1)
 
 		// Ensure that we have src types and they are valid.
 		srcTypes := flags.SrcTypes.Value()
 		if len(srcTypes) == 0 {
-			srcTypes = core.StringKeys(core.ValidSrcTypes)
+			srcTypes = core.SrcTypes()
 		} else {
 			err = core.EnsureSrcTypesValid(flags.SrcTypes.Value())
 			exitOnError(err, -1)
 		}
 
-		// Get the renders for the apps and ensure that they are OK.
-		// Unlike the 'render' command, we won't allow dry-run here as we want to
-		// update the rendered manifests in the output directory.
-		renders, err := core.GetRenders(cfg, appNames, flags.SrcNames.Value(), srcTypes, flags.Debug, flags.DryRun)
-		exitOnError(err, -1)
+		for _, target := range targets {
+			writeManifests(target.Config, target.OutputDir, srcTypes)
+		}
+		return nil
+	},
+}
 
-		// Clean output directories if the clean flag is set.
-		if flags.Clean {
-			for _, appName := range appNames {
-				appDir := path.Join(flags.OutputDir, appName)
-				printMsg(fmt.Sprintf("Cleaning up %s\n", appDir), true)
-				if err := os.RemoveAll(appDir); err != nil {
-					exitOnError(err, -1)
+// writeManifests writes the rendered manifests of the targeted apps and sources of a config to an output directory.
+func writeManifests(cfg *core.Config, outputDir string, srcTypes []string) {
+	// Get the app names to target.
+	appNames, err := getAppNames(cfg, flags.AppNames.Value())
+	exitOnError(err, -1)
+
+	// Get the renders for the apps and ensure that they are OK.
+	// Unlike the 'render' command, we won't allow dry-run here as we want to
+	// update the rendered manifests in the output directory.
+	renders, err := core.GetRenders(cfg, appNames, flags.SrcNames.Value(), srcTypes, getRenderOptions())
+	exitOnError(err, -1)
+
+	// Ensure that no plaintext secrets are written before anything in the output directory is changed.
+	manifests := core.GetManifests(renders)
+	exitOnError(core.EnsureNoPlaintextSecrets(cfg, manifests), -1)
+
+	// Keep the committed values of fields whose differences are ignored, before they are cleaned.
+	exitOnError(core.PreserveIgnoredDifferences(cfg, manifests, outputDir, getOutputOptions(cfg)), -1)
+
+	// Clean output directories if the clean flag is set. Output files of apps that have output path
+	// templates are not in a directory per app, so they are cleaned one by one.
+	if flags.Clean {
+		outputOpts := getOutputOptions(cfg)
+		for _, appName := range appNames {
+			if outputOpts.PathTemplates[appName] != "" {
+				outputs, err := core.GetOutputFiles(cfg, []string{appName}, nil, core.SrcTypes(), outputOpts)
+				exitOnError(err, -1)
+				for _, output := range outputs {
+					printMsg(fmt.Sprintf("Cleaning up %s\n", path.Join(outputDir, output)), true)
+					exitOnError(core.RemoveOutputPath(outputDir, output), -1)
 				}
+				continue
+			}
+			appDir := path.Join(outputDir, appName)
+			printMsg(fmt.Sprintf("Cleaning up %s\n", appDir), true)
+			if err := os.RemoveAll(appDir); err != nil {
+				exitOnError(err, -1)
 			}
 		}
+	}
 
-		// Write the rendered manifests to the output directory.
-		manifests := core.GetManifests(renders)
-		for _, manifest := range manifests {
-			path, err := manifest.Write(flags.OutputDir, flags.Flatten, flags.NoBanner)
-			exitOnError(err, -1)
+	// Write the rendered manifests to the output directory.
+	for _, manifest := range manifests {
+		paths, err := manifest.Write(outputDir, getOutputOptions(cfg))
+		exitOnError(err, -1)
+		for _, path := range paths {
 			printMsg(fmt.Sprintf("Wrote %s\n", path), false)
 		}
-		return nil
-	},
+	}
+
+	// Write the GitOps objects deploying the apps, if configured.
+	paths, err := core.WriteGitOpsObjects(cfg, outputDir, outputDir, getOutputOptions(cfg))
+	exitOnError(err, -1)
+	for _, p := range paths {
+		printMsg(fmt.Sprintf("Wrote %s\n", path.Join(outputDir, p)), false)
+	}
+
+	// Write the kustomization indexes of the output files if requested.
+	if flags.KustomizeIndex {
+		paths, err := core.WriteKustomizationIndexes(cfg, outputDir, getOutputOptions(cfg))
+		exitOnError(err, -1)
+		for _, p := range paths {
+			printMsg(fmt.Sprintf("Wrote %s\n", path.Join(outputDir, p)), false)
+		}
+	}
 }
 
 var checkCommand = &cli.Command{
@@ -274,64 +397,412 @@ var checkCommand = &cli.Command{
 		&outputDirFlag,
 		&appNamesFlag,
 		&debugFlag,
+		&jobsFlag,
+		&noCacheFlag,
 		&quietFlag,
 		&verboseFlag,
 		&flattenFlag,
+		&layoutFlag,
 		&noBannerFlag,
+		&kustomizeIndexFlag,
+		&statFlag,
+		&nameOnlyFlag,
+		&reportFormatFlag,
+		&reportFileFlag,
+		&envFlag,
+		&allEnvsFlag,
 	},
 	Action: func(c *cli.Context) error {
+		// Ensure that the report format is valid before rendering anything.
+		if flags.ReportFile != "" && flags.ReportFormat == "" {
+			flags.ReportFormat = core.ReportJSON
+		}
+		if flags.ReportFormat != "" && !slices.Contains(core.ReportFormats, flags.ReportFormat) {
+			exitOnError(fmt.Errorf("unsupported report format '%s' (valid: %s)", flags.ReportFormat, strings.Join(core.ReportFormats, ", ")), -1)
+		}
+
 		// Load the config file from disk.
 		cfg, err := core.LoadConfig(flags.RenderFile)
 		exitOnError(err, -1)
 
-		// Get the app names to target.
-		appNames, err := getAppNames(cfg, flags.AppNames.Value())
-		if err != nil {
-			exitOnError(err, -1)
+		// Get the environments to target. A report has the output files of a single output directory.
+		targets, err := getEnvTargets(cfg)
+		exitOnError(err, -1)
+		if len(targets) > 1 && flags.ReportFormat != "" {
+			exitOnError(errors.New("reports of more than one environment are not supported"), -1)
+		}
+
+		// Check all environments, and exit with a non-zero exit code if differences are found in any of them.
+		upToDate := true
+		for _, target := range targets {
+			if !checkManifests(target) {
+				upToDate = false
+			}
+		}
+		if !upToDate {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+// checkManifests checks that the rendered manifests of the targeted apps of the config of an environment are
+// up-to-date in its output directory, and returns true if they are.
+func checkManifests(target core.EnvironmentTarget) bool {
+	cfg, outputDir := target.Config, target.OutputDir
+
+	// Get the app names to target.
+	appNames, err := getAppNames(cfg, flags.AppNames.Value())
+	exitOnError(err, -1)
+
+	// Get the renders for the apps and ensure that they are OK.
+	// Unlike the 'render' command, we won't allow dry-run here as we want to
+	// update the rendered manifests in the output directory.
+	allSrcTypes := core.SrcTypes()
+	renders, err := core.GetRenders(cfg, appNames, nil, allSrcTypes, getRenderOptions())
+	exitOnError(err, -1)
+
+	// Ensure that no plaintext secrets would be written to the output directory.
+	manifests := core.GetManifests(renders)
+	exitOnError(core.EnsureNoPlaintextSecrets(cfg, manifests), -1)
+
+	// Exclude fields whose differences are ignored from comparison by keeping their committed values.
+	exitOnError(core.PreserveIgnoredDifferences(cfg, manifests, outputDir, getOutputOptions(cfg)), -1)
+
+	// Ensure that we're starting with a clean temp directory.
+	tempDir, err := os.MkdirTemp("", "manifestus")
+	exitOnError(err, -1)
+
+	// Ensure thaw we're cleaning up the temp directory when we're done.
+	defer func(path string) {
+		if flags.Verbose {
+			printMsg(fmt.Sprintf("Cleaning up manifest output directory: %s\n", path), true)
 		}
+		err := os.RemoveAll(path)
+		exitOnError(err, -1)
+	}(tempDir)
 
-		// Get the renders for the apps and ensure that they are OK.
-		// Unlike the 'render' command, we won't allow dry-run here as we want to
-		// update the rendered manifests in the output directory.
-		allSrcTypes := core.StringKeys(core.ValidSrcTypes)
-		renders, err := core.GetRenders(cfg, appNames, nil, allSrcTypes, flags.Debug, flags.DryRun)
+	// Write the rendered manifests to the output directory.
+	for _, manifest := range manifests {
+		printMsg(fmt.Sprintf("Writing %s", manifest.AppName), true)
+		_, err := manifest.Write(tempDir, getOutputOptions(cfg))
+		exitOnError(err, -1)
+	}
+
+	// Write the GitOps objects and the kustomization indexes if requested, as they are output files too.
+	generated, err := core.WriteGitOpsObjects(cfg, tempDir, outputDir, getOutputOptions(cfg))
+	exitOnError(err, -1)
+	if flags.KustomizeIndex {
+		indexes, err := core.WriteKustomizationIndexes(cfg, tempDir, getOutputOptions(cfg))
+		exitOnError(err, -1)
+		generated = append(generated, indexes...)
+	}
+
+	// Test if the contents of the output dir and the temp dir are the same.
+	diffs, err := core.DiffDirs(outputDir, tempDir)
+	exitOnError(err, -1)
+
+	// Write a report of the output files if requested. Reports written to stdout replace other output.
+	if flags.ReportFormat != "" {
+		report, err := core.NewCheckReport(outputDir, manifests, generated, diffs, getOutputOptions(cfg))
+		exitOnError(err, -1)
+		exitOnError(writeReport(report), -1)
+		if flags.ReportFile == "" {
+			flags.Quiet = true
+		}
+	}
+
+	// If there are differences, show them and report that the output directory is not up-to-date.
+	subject := "Rendered manifests" + getEnvSuffix(target)
+	if len(diffs) > 0 {
+		printDiffs(diffs)
+		printMsg(subject+" are not up-to-date with their sources", false)
+		return false
+	}
+	printMsg(subject+" are up-to-date with their sources", false)
+	return true
+}
+
+var diffCommand = &cli.Command{
+	Name:  "diff",
+	Usage: "Show differences of Kubernetes objects in rendered manifests from fresh renders of their sources.\n\nExit with status code 1 if differences are found.",
+	Flags: []cli.Flag{
+		&renderfileFlag,
+		&outputDirFlag,
+		&appNamesFlag,
+		&srcNamesFlag,
+		&srcTypesFlag,
+		&debugFlag,
+		&jobsFlag,
+		&noCacheFlag,
+		&quietFlag,
+		&flattenFlag,
+		&layoutFlag,
+		&ignoreFlag,
+		&envFlag,
+		&allEnvsFlag,
+	},
+	Action: func(c *cli.Context) error {
+		// Load the config file from disk.
+		cfg, err := core.LoadConfig(flags.RenderFile)
 		exitOnError(err, -1)
 
-		// Ensure that we're starting with a clean temp directory.
-		tempDir, err := os.MkdirTemp("", "manifestus")
+		// Get the environments to target.
+		targets, err := getEnvTargets(cfg)
 		exitOnError(err, -1)
 
-		// Ensure thaw we're cleaning up the temp directory when we're done.
-		defer func(path string) {
-			if flags.Verbose {
-				printMsg(fmt.Sprintf("Cleaning up manifest output directory: %s\n", path), true)
+		// Ensure that we have src types and they are valid.
+		srcTypes := flags.SrcTypes.Value()
+		if len(srcTypes) == 0 {
+			srcTypes = core.SrcTypes()
+		} else {
+			err = core.EnsureSrcTypesValid(flags.SrcTypes.Value())
+			exitOnError(err, -1)
+		}
+
+		// Diff all environments, and exit with a non-zero exit code if differences are found in any of them.
+		same := true
+		for _, target := range targets {
+			if !diffManifests(target, srcTypes) {
+				same = false
 			}
-			err := os.RemoveAll(path)
+		}
+		if !same {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+// diffManifests shows the differences of the objects in the output directory of an environment from fresh
+// renders of the targeted apps and sources of its config, and returns true if there are none.
+func diffManifests(target core.EnvironmentTarget, srcTypes []string) bool {
+	cfg, outputDir := target.Config, target.OutputDir
+
+	// Get the app names to target.
+	appNames, err := getAppNames(cfg, flags.AppNames.Value())
+	exitOnError(err, -1)
+
+	// Render the targeted sources, keeping the committed values of fields with ignored differences as
+	// the write command does.
+	renders, err := core.GetRenders(cfg, appNames, flags.SrcNames.Value(), srcTypes, getRenderOptions())
+	exitOnError(err, -1)
+	manifests := core.GetManifests(renders)
+	exitOnError(core.PreserveIgnoredDifferences(cfg, manifests, outputDir, getOutputOptions(cfg)), -1)
+
+	// Compare the objects in the output files of each source with those of its normalized render by
+	// their keys and fields.
+	diffs, err := core.DiffManifestObjects(manifests, outputDir, getOutputOptions(cfg), flags.Ignore.Value())
+	exitOnError(err, -1)
+
+	// When diffing all sources, the objects in orphaned files of the output directory, like those of removed
+	// sources, are removed objects too.
+	if len(flags.AppNames.Value()) == 0 && len(flags.SrcNames.Value()) == 0 && len(flags.SrcTypes.Value()) == 0 {
+		orphaned, err := core.GetOrphanedFiles(cfg, outputDir, getOutputOptions(cfg))
+		exitOnError(err, -1)
+		orphanedDiffs, err := core.DiffOrphanedObjects(outputDir, orphaned)
+		exitOnError(err, -1)
+		diffs = append(diffs, orphanedDiffs...)
+	}
+
+	// If there are differences, show them and report that they were found.
+	subject := "Rendered manifests" + getEnvSuffix(target)
+	if len(diffs) > 0 {
+		printObjectDiffs(diffs)
+		printMsg(subject+" have differences from their sources", false)
+		return false
+	}
+	printMsg(subject+" have no differences from their sources", false)
+	return true
+}
+
+var lintCommand = &cli.Command{
+	Name:  "lint",
+	Usage: "Lint Kubernetes objects in fresh renders of sources with built-in rules.\n\nExit with status code 1 if findings of the failing severity or above are found.",
+	Flags: []cli.Flag{
+		&renderfileFlag,
+		&appNamesFlag,
+		&srcNamesFlag,
+		&srcTypesFlag,
+		&debugFlag,
+		&jobsFlag,
+		&noCacheFlag,
+		&quietFlag,
+		&failOnFlag,
+		&envFlag,
+		&allEnvsFlag,
+	},
+	Action: func(c *cli.Context) error {
+		// Load the config file from disk.
+		cfg, err := core.LoadConfig(flags.RenderFile)
+		exitOnError(err, -1)
+
+		// Get the environments to target.
+		targets, err := getEnvTargets(cfg)
+		exitOnError(err, -1)
+
+		// Ensure that we have src types and they are valid.
+		srcTypes := flags.SrcTypes.Value()
+		if len(srcTypes) == 0 {
+			srcTypes = core.SrcTypes()
+		} else {
+			err = core.EnsureSrcTypesValid(flags.SrcTypes.Value())
 			exitOnError(err, -1)
-		}(tempDir)
+		}
+
+		// Lint all environments, and exit with a non-zero exit code if any has findings of the failing severity or above.
+		failed := false
+		for _, target := range targets {
+			if !lintManifests(target, srcTypes) {
+				failed = true
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+// lintManifests shows the findings of the built-in lint rules in fresh renders of the targeted apps and sources
+// of the config of an environment, and returns true if none are of the failing severity or above.
+func lintManifests(target core.EnvironmentTarget, srcTypes []string) bool {
+	cfg := target.Config
+
+	// Get the app names to target.
+	appNames, err := getAppNames(cfg, flags.AppNames.Value())
+	exitOnError(err, -1)
+
+	// Lint the objects in fresh renders of the targeted sources.
+	renders, err := core.GetRenders(cfg, appNames, flags.SrcNames.Value(), srcTypes, getRenderOptions())
+	exitOnError(err, -1)
+	findings, err := core.Lint(cfg, core.GetManifests(renders))
+	exitOnError(err, -1)
+
+	// Show the findings, and whether any are of the failing severity or above.
+	failed := false
+	counts := make(map[string]int)
+	for _, finding := range findings {
+		fmt.Println(finding)
+		counts[finding.Severity]++
+		failed = failed || core.IsLintSeverityAtLeast(finding.Severity, flags.FailOn)
+	}
+	printMsg(fmt.Sprintf("Found %d errors and %d warnings in rendered manifests%s", counts[core.LintError], counts[core.LintWarning], getEnvSuffix(target)), false)
+	return !failed
+}
+
+var validateManifestsCommand = &cli.Command{
+	Name:  "validate-manifests",
+	Usage: "Validate Kubernetes objects in fresh renders of sources against JSON schemas in a local directory, and custom resources against the schemas of CRDs rendered with them.\n\nExit with status code 1 if problems are found.",
+	Flags: []cli.Flag{
+		&renderfileFlag,
+		&appNamesFlag,
+		&srcNamesFlag,
+		&srcTypesFlag,
+		&debugFlag,
+		&jobsFlag,
+		&noCacheFlag,
+		&quietFlag,
+		&schemaDirFlag,
+		&kubeVersionFlag,
+		&ignoreUnknownKindsFlag,
+		&envFlag,
+		&allEnvsFlag,
+	},
+	Action: func(c *cli.Context) error {
+		// Load the config file from disk.
+		cfg, err := core.LoadConfig(flags.RenderFile)
+		exitOnError(err, -1)
 
-		// Write the rendered manifests to the output directory.
-		manifests := core.GetManifests(renders)
-		for _, manifest := range manifests {
-			printMsg(fmt.Sprintf("Writing %s", manifest.AppName), true)
-			_, err := manifest.Write(tempDir, flags.Flatten, flags.NoBanner)
+		// Ensure that the schema directory exists before rendering anything. Without one, only custom
+		// resources of CRDs rendered with them are validated.
+		var validator *core.SchemaValidator
+		if flags.SchemaDir != "" {
+			validator, err = core.NewSchemaValidator(flags.SchemaDir, flags.KubeVersion)
 			exitOnError(err, -1)
 		}
 
-		// Test if the contents of the output dir and the temp dir are the same.
-		diff, err := diffDirs(flags.OutputDir, tempDir)
+		// Get the environments to target.
+		targets, err := getEnvTargets(cfg)
 		exitOnError(err, -1)
 
-		// If there are differences, show them and exit with a non-zero exit code to indicate differences found.
-		if len(diff) > 0 {
-			printMsg("Rendered manifests are not up-to-date with their sources", false)
+		// Ensure that we have src types and they are valid.
+		srcTypes := flags.SrcTypes.Value()
+		if len(srcTypes) == 0 {
+			srcTypes = core.SrcTypes()
+		} else {
+			err = core.EnsureSrcTypesValid(flags.SrcTypes.Value())
+			exitOnError(err, -1)
+		}
+
+		// Validate all environments, and exit with a non-zero exit code if problems are found in any of them.
+		valid := true
+		for _, target := range targets {
+			if !validateManifests(target, srcTypes, validator) {
+				valid = false
+			}
+		}
+		if !valid {
 			os.Exit(1)
 		}
-		printMsg("Rendered manifests are up-to-date with their sources", false)
 		return nil
 	},
 }
 
+// validateManifests shows the problems of the objects in fresh renders of the targeted apps and sources of the
+// config of an environment, and returns true if there are none.
+func validateManifests(target core.EnvironmentTarget, srcTypes []string, validator *core.SchemaValidator) bool {
+	cfg := target.Config
+
+	// Get the app names to target.
+	appNames, err := getAppNames(cfg, flags.AppNames.Value())
+	exitOnError(err, -1)
+
+	// Validate the objects in fresh renders of the targeted sources.
+	renders, err := core.GetRenders(cfg, appNames, flags.SrcNames.Value(), srcTypes, getRenderOptions())
+	exitOnError(err, -1)
+	problems, err := core.ValidateManifests(core.GetManifests(renders), validator, flags.IgnoreUnknown)
+	exitOnError(err, -1)
+
+	// If there are problems, show each on its own line and report that they were found.
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Println(problem.Error())
+		}
+		printMsg(fmt.Sprintf("Found %d problems in rendered manifests%s", len(problems), getEnvSuffix(target)), false)
+		return false
+	}
+	printMsg("Rendered manifests"+getEnvSuffix(target)+" are valid", false)
+	return true
+}
+
+var cacheCommand = &cli.Command{
+	Name:  "cache",
+	Usage: "Manage the cache of rendered sources",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "prune",
+			Usage: "Remove cached renders not used within a maximum age, or all cached renders if none given",
+			Flags: []cli.Flag{
+				&renderfileFlag,
+				&maxAgeFlag,
+				&quietFlag,
+			},
+			Action: func(c *cli.Context) error {
+				// Load the config file from disk, as the cache is kept relative to it.
+				_, err := core.LoadConfig(flags.RenderFile)
+				exitOnError(err, -1)
+
+				// Remove the cached renders and report how many were removed.
+				removed, err := core.PruneCache(flags.MaxAge)
+				exitOnError(err, -1)
+				printMsg(fmt.Sprintf("Removed %d cached renders from %s", removed, core.CacheDir()), false)
+				return nil
+			},
+		},
+	},
+}
+
 var versionCommand = &cli.Command{
 	Name:  "version",
 	Usage: "Show version",
@@ -343,20 +814,37 @@ var versionCommand = &cli.Command{
 
 // flags is used to store the values of the flags passed to the CLI
 var flags struct {
-	RenderFile string
-	OutputDir  string
-	AppNames   cli.StringSlice
-	SrcNames   cli.StringSlice
-	SrcTypes   cli.StringSlice
-	Clean      bool
-	Debug      bool
-	DryRun     bool
-	Quiet      bool
-	Verbose    bool
-	Latest     bool
-	Outdated   bool
-	Flatten    bool
-	NoBanner   bool
+	RenderFile     string
+	OutputDir      string
+	AppNames       cli.StringSlice
+	SrcNames       cli.StringSlice
+	SrcTypes       cli.StringSlice
+	Clean          bool
+	Debug          bool
+	DryRun         bool
+	Quiet          bool
+	Verbose        bool
+	Latest         bool
+	Outdated       bool
+	Flatten        bool
+	Layout         string
+	KustomizeIndex bool
+	NoBanner       bool
+	ShowFiltered   bool
+	Jobs           int
+	NoCache        bool
+	MaxAge         time.Duration
+	Stat           bool
+	NameOnly       bool
+	Ignore         cli.StringSlice
+	ReportFormat   string
+	FailOn         string
+	SchemaDir      string
+	KubeVersion    string
+	ReportFile     string
+	IgnoreUnknown  bool
+	Env            string
+	AllEnvs        bool
 }
 
 var renderfileFlag = cli.StringFlag{
@@ -392,7 +880,7 @@ var srcNamesFlag = cli.StringSliceFlag{
 var srcTypesFlag = cli.StringSliceFlag{
 	Name:        "type",
 	Aliases:     []string{"t"},
-	Usage:       fmt.Sprintf("Specify the type of source to render (valid: %s)", strings.Join(core.StringKeys(core.ValidSrcTypes), " | ")),
+	Usage:       fmt.Sprintf("Specify the type of source to render (valid: %s)", strings.Join(core.SrcTypes(), " | ")),
 	Destination: &flags.SrcTypes,
 }
 
@@ -442,8 +930,74 @@ var outdatedFlag = cli.BoolFlag{
 
 var flattenFlag = cli.BoolFlag{
 	Name:        "flatten",
-	Usage:       "Flatten the output of the rendered manifests",
+	Usage:       fmt.Sprintf("Flatten the output of the rendered manifests (deprecated: use the output path template '%s' instead)", core.FlattenPathTemplate),
 	Destination: &flags.Flatten,
+	Action: func(c *cli.Context, flatten bool) error {
+		if !flatten {
+			return nil
+		}
+		if flags.Layout == core.LayoutResource {
+			exitOnError(fmt.Errorf("the --flatten flag cannot be used with the '%s' layout", core.LayoutResource), -1)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: the --flatten flag is deprecated, use the output path template '%s' instead\n", core.FlattenPathTemplate)
+		return nil
+	},
+}
+
+var layoutFlag = cli.StringFlag{
+	Name:        "layout",
+	Usage:       "Specify the layout of output files: 'source' for a file per source, 'resource' for a file per object",
+	Destination: &flags.Layout,
+	Value:       core.LayoutSource,
+	Action: func(c *cli.Context, layout string) error {
+		exitOnError(core.EnsureLayoutValid(layout), -1)
+		return nil
+	},
+}
+
+var kustomizeIndexFlag = cli.BoolFlag{
+	Name:        "kustomize-index",
+	Usage:       "Generate kustomization.yaml files listing the output files in app directories and the output directory",
+	Destination: &flags.KustomizeIndex,
+}
+
+var jobsFlag = cli.IntFlag{
+	Name:        "jobs",
+	Aliases:     []string{"j"},
+	Usage:       "Specify the maximum number of sources to render concurrently",
+	Destination: &flags.Jobs,
+	Value:       1,
+}
+
+var noCacheFlag = cli.BoolFlag{
+	Name:        "no-cache",
+	Usage:       "Render all sources without reusing cached renders",
+	Destination: &flags.NoCache,
+}
+
+var envFlag = cli.StringFlag{
+	Name:        "env",
+	Aliases:     []string{"e"},
+	Usage:       "Specify the name of an environment to target instead of all environments",
+	Destination: &flags.Env,
+}
+
+var allEnvsFlag = cli.BoolFlag{
+	Name:        "all-envs",
+	Usage:       "Target all environments, failing if there are none",
+	Destination: &flags.AllEnvs,
+}
+
+var showFilteredFlag = cli.BoolFlag{
+	Name:        "show-filtered",
+	Usage:       "Print the objects removed by the include and exclude filters of sources instead of the rendered manifests",
+	Destination: &flags.ShowFiltered,
+}
+
+var maxAgeFlag = cli.DurationFlag{
+	Name:        "max-age",
+	Usage:       "Specify the maximum age of cached renders to keep since their last use (e.g. 168h)",
+	Destination: &flags.MaxAge,
 }
 
 var noBannerFlag = cli.BoolFlag{
@@ -452,16 +1006,203 @@ var noBannerFlag = cli.BoolFlag{
 	Destination: &flags.NoBanner,
 }
 
-// diffDirs runs the `diff` command to compare the contents of two directories.
-// An empty string is returned if the directories are the same.
-// An error is returned if the `diff` command fails.
-func diffDirs(dir1, dir2 string) (string, error) {
-	cmd := exec.Command("diff", "-r", dir1, dir2)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &out
-	err := cmd.Run()
-	return out.String(), err
+var statFlag = cli.BoolFlag{
+	Name:        "stat",
+	Usage:       "Show a summary of changed lines per file instead of unified diffs",
+	Destination: &flags.Stat,
+}
+
+var ignoreFlag = cli.StringSliceFlag{
+	Name:        "ignore",
+	Usage:       "Specify JSON Pointers of fields to ignore, whose segments may be '*' (e.g. /spec/template/metadata/annotations/checksum~1config)",
+	Destination: &flags.Ignore,
+}
+
+var failOnFlag = cli.StringFlag{
+	Name:        "fail-on",
+	Usage:       fmt.Sprintf("Specify the severity of lint findings failing the command, and of findings more severe (one of: %s)", strings.Join(core.LintSeverities, ", ")),
+	Destination: &flags.FailOn,
+	Value:       core.LintError,
+	Action: func(c *cli.Context, severity string) error {
+		if !slices.Contains(core.LintSeverities, severity) {
+			exitOnError(fmt.Errorf("unsupported lint severity '%s' (valid: %s)", severity, strings.Join(core.LintSeverities, ", ")), -1)
+		}
+		return nil
+	},
+}
+
+var schemaDirFlag = cli.StringFlag{
+	Name:        "schema-dir",
+	Usage:       "Specify the local directory of the JSON schemas of Kubernetes objects",
+	Destination: &flags.SchemaDir,
+}
+
+var kubeVersionFlag = cli.StringFlag{
+	Name:        "kube-version",
+	Usage:       "Specify the version of Kubernetes of the JSON schemas, like '1.31.0' or 'master'",
+	Destination: &flags.KubeVersion,
+	Value:       "master",
+}
+
+var ignoreUnknownKindsFlag = cli.BoolFlag{
+	Name:        "ignore-unknown-kinds",
+	Usage:       "Skip objects of kinds without JSON schemas instead of reporting them",
+	Destination: &flags.IgnoreUnknown,
+}
+
+var reportFormatFlag = cli.StringFlag{
+	Name:        "report-format",
+	Usage:       fmt.Sprintf("Write a report of the output files in a format (one of: %s)", strings.Join(core.ReportFormats, ", ")),
+	Destination: &flags.ReportFormat,
+}
+
+var reportFileFlag = cli.StringFlag{
+	Name:        "report-file",
+	Usage:       "Specify the file to write the report to instead of stdout, in json format unless --report-format is given",
+	Destination: &flags.ReportFile,
+}
+
+var nameOnlyFlag = cli.BoolFlag{
+	Name:        "name-only",
+	Usage:       "Show only the names of changed files instead of unified diffs",
+	Destination: &flags.NameOnly,
+}
+
+// printDiffs prints the differences of files in the output directory from freshly rendered manifests,
+// as the names of the files with --name-only, a summary of changes per file with --stat, or as a list
+// of added, removed and modified files followed by the unified diffs of modified files by default.
+func printDiffs(diffs []core.FileDiff) {
+	if flags.Quiet {
+		return
+	}
+	if flags.NameOnly {
+		for _, diff := range diffs {
+			fmt.Println(diff.Path)
+		}
+		return
+	}
+	if flags.Stat {
+		printDiffStat(diffs)
+		return
+	}
+	statusFmts := map[string]func(format string, a ...interface{}) string{
+		core.FileAdded:    color.New(color.FgGreen).SprintfFunc(),
+		core.FileRemoved:  color.New(color.FgRed).SprintfFunc(),
+		core.FileModified: color.New(color.FgYellow).SprintfFunc(),
+	}
+	for _, diff := range diffs {
+		fmt.Printf("%s %s\n", statusFmts[diff.Status]("%-8s", diff.Status), diff.Path)
+	}
+	for _, diff := range diffs {
+		if diff.Unified != "" {
+			fmt.Println()
+			printUnifiedDiff(diff.Unified)
+		}
+	}
+	fmt.Println()
+}
+
+// writeReport writes a check report to the report file, or to stdout if none is given.
+func writeReport(report *core.CheckReport) error {
+	if flags.ReportFile == "" {
+		return report.Write(os.Stdout, flags.ReportFormat)
+	}
+	file, err := os.Create(flags.ReportFile)
+	if err != nil {
+		return err
+	}
+	if err := report.Write(file, flags.ReportFormat); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// printUnifiedDiff prints a unified diff with colored headers, hunk ranges, and inserted and deleted lines.
+func printUnifiedDiff(unified string) {
+	headerFmt := color.New(color.Bold).SprintFunc()
+	hunkFmt := color.New(color.FgCyan).SprintFunc()
+	insertedFmt := color.New(color.FgGreen).SprintFunc()
+	deletedFmt := color.New(color.FgRed).SprintFunc()
+	for _, line := range strings.Split(strings.TrimSuffix(unified, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			line = headerFmt(line)
+		case strings.HasPrefix(line, "@@"):
+			line = hunkFmt(line)
+		case strings.HasPrefix(line, "+"):
+			line = insertedFmt(line)
+		case strings.HasPrefix(line, "-"):
+			line = deletedFmt(line)
+		}
+		fmt.Println(line)
+	}
+}
+
+// printObjectDiffs prints the objects added, removed and changed, followed by the field-level
+// differences of changed objects, unless the quiet flag is set.
+func printObjectDiffs(diffs []core.ObjectDiff) {
+	if flags.Quiet {
+		return
+	}
+	statusFmts := map[string]func(format string, a ...interface{}) string{
+		core.ObjectAdded:   color.New(color.FgGreen).SprintfFunc(),
+		core.ObjectRemoved: color.New(color.FgRed).SprintfFunc(),
+		core.ObjectChanged: color.New(color.FgYellow).SprintfFunc(),
+	}
+	for _, diff := range diffs {
+		if diff.File != "" {
+			fmt.Printf("%s %s (orphaned file '%s')\n", statusFmts[diff.Status]("%-7s", diff.Status), diff.ObjectKey, diff.File)
+		} else {
+			fmt.Printf("%s %s (%s '%s' of app '%s')\n", statusFmts[diff.Status]("%-7s", diff.Status), diff.ObjectKey, diff.SrcType, diff.SrcName, diff.AppName)
+		}
+		for _, field := range diff.Fields {
+			switch field.Status {
+			case core.ObjectAdded:
+				fmt.Printf("  %s %s: %s\n", statusFmts[field.Status]("+"), field.Path, field.New)
+			case core.ObjectRemoved:
+				fmt.Printf("  %s %s: %s\n", statusFmts[field.Status]("-"), field.Path, field.Old)
+			default:
+				fmt.Printf("  %s %s: %s -> %s\n", statusFmts[field.Status]("~"), field.Path, field.Old, field.New)
+			}
+		}
+	}
+}
+
+// maxDiffStatWidth is the maximum width of the bars of inserted and deleted lines printed with --stat.
+const maxDiffStatWidth = 40
+
+// printDiffStat prints the number of lines changed per file with bars of inserted and deleted lines,
+// scaled to fit the maximum width, followed by the totals of files, insertions and deletions.
+func printDiffStat(diffs []core.FileDiff) {
+	pathWidth, maxChanges, insertions, deletions := 0, 0, 0, 0
+	for _, diff := range diffs {
+		pathWidth = max(pathWidth, len(diff.Path))
+		maxChanges = max(maxChanges, diff.Insertions+diff.Deletions)
+		insertions += diff.Insertions
+		deletions += diff.Deletions
+	}
+	for _, diff := range diffs {
+		inserted, deleted := diff.Insertions, diff.Deletions
+		if maxChanges > maxDiffStatWidth {
+			inserted = (inserted*maxDiffStatWidth + maxChanges - 1) / maxChanges
+			deleted = (deleted*maxDiffStatWidth + maxChanges - 1) / maxChanges
+		}
+		fmt.Printf(" %-*s | %d %s%s\n", pathWidth, diff.Path, diff.Insertions+diff.Deletions,
+			color.GreenString(strings.Repeat("+", inserted)), color.RedString(strings.Repeat("-", deleted)))
+	}
+	fmt.Printf(" %d %s changed, %d %s(+), %d %s(-)\n",
+		len(diffs), plural(len(diffs), "file", "files"),
+		insertions, plural(insertions, "insertion", "insertions"),
+		deletions, plural(deletions, "deletion", "deletions"))
+}
+
+// plural returns the singular or plural form of a word for a count.
+func plural(count int, singular, pluralForm string) string {
+	if count == 1 {
+		return singular
+	}
+	return pluralForm
 }
 
 // exitOnError prints the error to stdout and exits with the given exit code.
@@ -486,6 +1227,37 @@ func printMsg(msg string, verboseOnly bool) {
 	exitOnError(err, -1)
 }
 
+// getRenderOptions returns the render options from the flags passed to the CLI.
+func getRenderOptions() core.RenderOptions {
+	return core.RenderOptions{
+		Debug:   flags.Debug,
+		DryRun:  flags.DryRun,
+		Jobs:    flags.Jobs,
+		NoCache: flags.NoCache,
+	}
+}
+
+// getOutputOptions returns the output options from the flags passed to the CLI and the output path templates of the config.
+// The deprecated flatten flag sets the flatten output path template of all apps, so it cannot be used with the templates of the config.
+func getOutputOptions(cfg *core.Config) core.OutputOptions {
+	pathTemplates := cfg.OutputPathTemplates()
+	if flags.Flatten {
+		if len(pathTemplates) > 0 {
+			exitOnError(errors.New("the --flatten flag cannot be used with output path templates in the config"), -1)
+		}
+		for _, app := range cfg.Renderfile.Apps {
+			pathTemplates[app.Name] = core.FlattenPathTemplate
+		}
+	}
+	return core.OutputOptions{
+		NoBanner:       flags.NoBanner,
+		Layout:         flags.Layout,
+		PathTemplates:  pathTemplates,
+		Normalizations: cfg.OutputNormalizations(),
+		KustomizeIndex: flags.KustomizeIndex,
+	}
+}
+
 // getAppNames returns the app names from the config file or the enabled apps if none are specified.
 func getAppNames(cfg *core.Config, appNames []string) ([]string, error) {
 	if len(appNames) == 0 {
@@ -499,26 +1271,56 @@ func getAppNames(cfg *core.Config, appNames []string) ([]string, error) {
 	return appNames, nil
 }
 
-// getTypesTable returns a table of source type names and descriptions.
-func getTypesTable(types map[string]string) (table.Table, error) {
+// getEnvTargets returns the environments targeted by the env flags, or all environments of the config if none
+// is targeted, or the config itself with the output directory of the flags if it has no environments.
+func getEnvTargets(cfg *core.Config) ([]core.EnvironmentTarget, error) {
+	switch {
+	case flags.Env != "" && flags.AllEnvs:
+		return nil, errors.New("the --env and --all-envs flags cannot be used together")
+	case flags.Env != "":
+		return cfg.EnvironmentTargets([]string{flags.Env}, flags.OutputDir)
+	case flags.AllEnvs && len(cfg.EnvironmentNames()) == 0:
+		return nil, errors.New("no environments in config")
+	}
+	return cfg.EnvironmentTargets(nil, flags.OutputDir)
+}
+
+// getEnvSuffix returns the suffix of messages about the rendered manifests of an environment naming it, if any.
+func getEnvSuffix(target core.EnvironmentTarget) string {
+	if target.Name == "" {
+		return ""
+	}
+	return fmt.Sprintf(" of environment '%s'", target.Name)
+}
+
+// getTypesTable returns a table of source types of renderers with their config keys, descriptions, capabilities and tools.
+func getTypesTable(renderers []core.Renderer) (table.Table, error) {
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
 	columnFmt := color.New(color.FgYellow).SprintfFunc()
 
-	srcTypeNames := make([]string, 0, len(types))
-	for name := range types {
-		srcTypeNames = append(srcTypeNames, name)
-	}
-	sort.Strings(srcTypeNames)
+	sorted := append([]core.Renderer(nil), renderers...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Type() < sorted[j].Type()
+	})
 
-	tbl := table.New("Name", "Description")
+	tbl := table.New("Name", "Key", "Description", "Capabilities", "Tools")
 	tbl.WithHeaderFormatter(headerFmt).WithFirstColumnFormatter(columnFmt)
 
-	for _, name := range srcTypeNames {
-		tbl.AddRow(name, types[name])
+	for _, r := range sorted {
+		capabilities := r.Capabilities()
+		tbl.AddRow(r.Type(), r.Key(), r.Description(), joinOrNone(capabilities.Strings()), joinOrNone(capabilities.Tools))
 	}
 	return tbl, nil
 }
 
+// joinOrNone returns a comma-separated list of items, or "none" if there are none.
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
+
 // getChartsTable returns a table of charts.
 func getChartsTable(charts []*core.Chart, includeLatest, onlyOutdated bool) (table.Table, error) {
 	headerFmt := color.New(color.FgGreen, color.Underline).SprintfFunc()
@@ -0,0 +1,480 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// cacheFileExt is the file extension of cached renders in the cache directory.
+const cacheFileExt = ".yaml"
+
+// CacheDir returns the directory where renders are cached, relative to the directory of the loaded config.
+func CacheDir() string {
+	return path.Join(configDir, ".manifestus", "cache")
+}
+
+// PruneCache removes cached renders not used within maxAge, or all cached renders if maxAge is zero,
+// and returns the number of cached renders removed.
+func PruneCache(maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(CacheDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), cacheFileExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return removed, err
+		}
+		if maxAge > 0 && time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		if err := os.Remove(path.Join(CacheDir(), entry.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// cachedRender returns a render from the cache if one exists for its cache key, otherwise it
+// renders and caches it. On cache hits, render is called with dry-run enabled to complete the
+// render with its command line without executing it. Renders are not cached if caching is
+// disabled, or if the cache key is empty or cannot be computed.
+func cachedRender(opts RenderOptions, key func() (string, error), render func(RenderOptions) (*Render, error)) (*Render, error) {
+	if opts.NoCache || opts.DryRun {
+		return render(opts)
+	}
+	k, err := key()
+	if err != nil || k == "" {
+		// Render without the cache, leaving any errors to be reported by the render itself.
+		return render(opts)
+	}
+	if stdout, ok := readCachedRender(k); ok {
+		dryRunOpts := opts
+		dryRunOpts.DryRun = true
+		result, err := render(dryRunOpts)
+		if err != nil {
+			return result, err
+		}
+		result.Stdout = stdout
+		return result, nil
+	}
+	result, err := render(opts)
+	if err == nil {
+		// Failing to cache a render is not a failure to render it, so any error is ignored.
+		_ = writeCachedRender(k, result.Stdout)
+	}
+	return result, err
+}
+
+// readCachedRender returns the cached stdout of a render by its cache key, if it exists.
+// The modification time of a cached render is updated when read to track its last use.
+func readCachedRender(key string) ([]byte, bool) {
+	p := path.Join(CacheDir(), key+cacheFileExt)
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(p, now, now)
+	return data, true
+}
+
+// writeCachedRender writes the stdout of a render to the cache by its cache key.
+// It is first written to a temporary file and renamed so that concurrent readers never see partial renders.
+func writeCachedRender(key string, stdout []byte) error {
+	if err := os.MkdirAll(CacheDir(), 0755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(CacheDir(), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(stdout); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path.Join(CacheDir(), key+cacheFileExt))
+}
+
+// cacheKey hashes the inputs of a render into a content-addressed cache key.
+type cacheKey struct {
+	hash hash.Hash
+}
+
+// newCacheKey returns a new cache key for a render of a source definition of an app.
+func newCacheKey(appName, srcType string, src any, opts RenderOptions) (*cacheKey, error) {
+	def, err := yaml.Marshal(src)
+	if err != nil {
+		return nil, err
+	}
+	k := &cacheKey{hash: sha256.New()}
+	k.addString("app", appName)
+	k.addString("type", srcType)
+	k.addString("source", string(def))
+	k.addString("debug", fmt.Sprint(opts.Debug))
+	return k, nil
+}
+
+// addString adds a named string to the cache key.
+func (k *cacheKey) addString(name, value string) {
+	_, _ = fmt.Fprintf(k.hash, "%s=%d:%s\n", name, len(value), value)
+}
+
+// addFile adds the path and contents of a file to the cache key.
+func (k *cacheKey) addFile(p string) error {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	k.addString("file", p)
+	k.addString("data", string(data))
+	return nil
+}
+
+// addDir adds the paths and contents of all files in a directory tree to the cache key.
+func (k *cacheKey) addDir(dir string) error {
+	return filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		return k.addFile(p)
+	})
+}
+
+// addToolVersion adds the version of an external render tool to the cache key.
+func (k *cacheKey) addToolVersion(tool string) error {
+	version, err := getToolVersion(tool)
+	if err != nil {
+		return err
+	}
+	k.addString(tool, version)
+	return nil
+}
+
+// sum returns the cache key as a hex-encoded string.
+func (k *cacheKey) sum() string {
+	return hex.EncodeToString(k.hash.Sum(nil))
+}
+
+// toolVersionCmdlines is a mapping of external render tools to command lines printing their versions.
+var toolVersionCmdlines = map[string]string{
+	"helm":      "helm version --short",
+	"helmfile":  "helmfile --version",
+	"kustomize": "kustomize version",
+}
+
+// toolVersions caches the versions of external render tools, as they do not change during a run.
+var toolVersions = struct {
+	sync.Mutex
+	versions map[string]string
+}{versions: make(map[string]string)}
+
+// getToolVersion returns the version of an external render tool.
+func getToolVersion(tool string) (string, error) {
+	toolVersions.Lock()
+	defer toolVersions.Unlock()
+	if version, ok := toolVersions.versions[tool]; ok {
+		return version, nil
+	}
+	_, stdout, stderr, exit, err := execCmd(toolVersionCmdlines[tool], "")
+	if err != nil {
+		return "", err
+	}
+	if exit != 0 {
+		return "", fmt.Errorf("failed to get %s version: %s", tool, string(stderr))
+	}
+	version := strings.TrimSpace(string(stdout))
+	toolVersions.versions[tool] = version
+	return version, nil
+}
+
+// getReleaseCacheKey returns the cache key of a render of a Helm chart release.
+// It covers the release definition, its values files, its local chart if any, or
+// its Helmfile and the files it references, and the versions of the tools or SDK used.
+// Remote charts without a pinned version resolve to their latest matching version, which
+// may change between renders, so releases of such charts, in the config or in their
+// Helmfile, are not cached and an empty key is returned for them.
+func getReleaseCacheKey(appName string, release Release, helmfile string, opts RenderOptions) (string, error) {
+	if release.Chart != "" && !isPinnedVersion(release.Version) && !pathExists(getReleaseChart(release)) {
+		return "", nil
+	}
+	if release.Chart == "" {
+		if unpinned, err := hasHelmfileUnpinnedCharts(helmfile); err != nil || unpinned {
+			return "", err
+		}
+	}
+	k, err := newCacheKey(appName, "release", release, opts)
+	if err != nil {
+		return "", err
+	}
+	if release.Chart != "" && release.Engine == engineBuiltin {
+		k.addString("helm-sdk", getModuleVersion(helmModulePath))
+	} else if err := k.addToolVersion("helm"); err != nil {
+		return "", err
+	}
+	if release.Chart != "" {
+		for _, values := range getReleaseValues(release) {
+			if err := k.addFile(values); err != nil {
+				return "", err
+			}
+		}
+		chart := getReleaseChart(release)
+		if info, err := os.Stat(chart); err == nil {
+			if info.IsDir() {
+				err = k.addDir(chart)
+			} else {
+				err = k.addFile(chart)
+			}
+			if err != nil {
+				return "", err
+			}
+		}
+		return k.sum(), nil
+	}
+	if err := k.addToolVersion("helmfile"); err != nil {
+		return "", err
+	}
+	if err := k.addFile(helmfile); err != nil {
+		return "", err
+	}
+	files, err := getHelmfileReferencedFiles(helmfile)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		if err := k.addFile(file); err != nil {
+			return "", err
+		}
+	}
+	return k.sum(), nil
+}
+
+// isPinnedVersion returns true if a chart version is an exact version rather than empty or a range of versions.
+// Templated versions of Helmfiles are pinned, as they are expanded from the files they reference.
+func isPinnedVersion(version string) bool {
+	if strings.Contains(version, "{{") {
+		return true
+	}
+	if version == "" || strings.ContainsAny(version, "^~<>=*| ") {
+		return false
+	}
+	for _, part := range strings.Split(version, ".") {
+		if part == "x" || part == "X" {
+			return false
+		}
+	}
+	return true
+}
+
+// getKustomizationCacheKey returns the cache key of a render of a Kustomization.
+// It covers the kustomization definition, the contents of its directory, its components,
+// and the local directories and files they reference, and the kustomize version. Remote
+// kustomizations, and kustomizations referencing remote resources or Helm charts, whose
+// contents may change between renders, are not cached, so an empty key is returned for them.
+func getKustomizationCacheKey(appName string, kustomization Kustomization, opts RenderOptions) (string, error) {
+	if isURL(kustomization.Source) {
+		return "", nil
+	}
+	dir, err := getKustomizationDir(kustomization)
+	if err != nil {
+		return "", err
+	}
+	refs := &kustomizationRefs{}
+	if err := refs.addDir(dir); err != nil {
+		return "", err
+	}
+	for _, component := range getKustomizationComponents(kustomization) {
+		if err := refs.add(component); err != nil {
+			return "", err
+		}
+	}
+	if refs.remote {
+		return "", nil
+	}
+	k, err := newCacheKey(appName, "kustomization", kustomization, opts)
+	if err != nil {
+		return "", err
+	}
+	if kustomization.Engine == engineBuiltin {
+		k.addString("kustomize-api", getModuleVersion(kustomizeModulePath))
+	} else if err := k.addToolVersion("kustomize"); err != nil {
+		return "", err
+	}
+	for _, dir := range refs.dirs {
+		if err := k.addDir(dir); err != nil {
+			return "", err
+		}
+	}
+	for _, file := range refs.files {
+		if err := k.addFile(file); err != nil {
+			return "", err
+		}
+	}
+	return k.sum(), nil
+}
+
+// kustomizationFileNames are the file names recognized by kustomize as kustomization files.
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// kustomizationRefs are the local directories and files referenced by kustomizations, recursively.
+type kustomizationRefs struct {
+	// dirs are the kustomization directories and local directories referenced by their resources,
+	// bases and components.
+	dirs []string
+
+	// files are the local files referenced by kustomizations outside of dirs.
+	files []string
+
+	// remote is true if a reference is not a local directory or file, or a kustomization renders Helm charts.
+	remote bool
+}
+
+// kustomizationFile represents the fields of a kustomization file referencing other directories and files.
+type kustomizationFile struct {
+	Resources             []string                 `yaml:"resources"`
+	Bases                 []string                 `yaml:"bases"`
+	Components            []string                 `yaml:"components"`
+	CRDs                  []string                 `yaml:"crds"`
+	Configurations        []string                 `yaml:"configurations"`
+	Generators            []string                 `yaml:"generators"`
+	Transformers          []string                 `yaml:"transformers"`
+	Validators            []string                 `yaml:"validators"`
+	PatchesStrategicMerge []string                 `yaml:"patchesStrategicMerge"`
+	Patches               []kustomizationPath      `yaml:"patches"`
+	PatchesJSON6902       []kustomizationPath      `yaml:"patchesJson6902"`
+	Replacements          []kustomizationPath      `yaml:"replacements"`
+	ConfigMapGenerator    []kustomizationGenerator `yaml:"configMapGenerator"`
+	SecretGenerator       []kustomizationGenerator `yaml:"secretGenerator"`
+	OpenAPI               kustomizationPath        `yaml:"openapi"`
+	HelmCharts            []any                    `yaml:"helmCharts"`
+}
+
+// kustomizationPath represents the 'path' field of the fields of a kustomization file referencing a file.
+type kustomizationPath struct {
+	Path string `yaml:"path"`
+}
+
+// kustomizationGenerator represents the fields of a ConfigMap or Secret generator referencing files.
+type kustomizationGenerator struct {
+	Files []string `yaml:"files"`
+	Envs  []string `yaml:"envs"`
+	Env   string   `yaml:"env"`
+}
+
+// add adds a local directory or file referenced by a kustomization. References that are neither, like
+// remote bases, are remote.
+func (r *kustomizationRefs) add(ref string) error {
+	info, err := os.Stat(ref)
+	switch {
+	case err != nil:
+		r.remote = true
+		return nil
+	case info.IsDir():
+		return r.addDir(ref)
+	}
+	ref = filepath.Clean(ref)
+	if r.contains(ref) || contains(r.files, ref) {
+		return nil
+	}
+	r.files = append(r.files, ref)
+	return nil
+}
+
+// addDir adds a kustomization directory and all local directories and files referenced by its
+// kustomization file, recursively, skipping directories already added.
+func (r *kustomizationRefs) addDir(dir string) error {
+	dir = filepath.Clean(dir)
+	if r.contains(dir) {
+		return nil
+	}
+	r.dirs = append(r.dirs, dir)
+	for _, name := range kustomizationFileNames {
+		data, err := os.ReadFile(path.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		file := kustomizationFile{}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", path.Join(dir, name), err)
+		}
+		if len(file.HelmCharts) > 0 {
+			r.remote = true
+		}
+		for _, ref := range file.refs() {
+			if err := r.add(path.Join(dir, ref)); err != nil {
+				return err
+			}
+		}
+		break
+	}
+	return nil
+}
+
+// contains returns true if a path is in one of the directories added.
+func (r *kustomizationRefs) contains(p string) bool {
+	for _, dir := range r.dirs {
+		if p == dir || strings.HasPrefix(p, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// refs returns the paths of the directories and files referenced by a kustomization file, relative to its
+// directory. Inline patches and plugin configurations are not references, so they are skipped.
+func (f kustomizationFile) refs() []string {
+	refs := slices.Concat(f.Resources, f.Bases, f.Components, f.CRDs, f.Configurations, f.Generators, f.Transformers, f.Validators, f.PatchesStrategicMerge)
+	for _, patch := range slices.Concat(f.Patches, f.PatchesJSON6902, f.Replacements) {
+		refs = append(refs, patch.Path)
+	}
+	for _, generator := range slices.Concat(f.ConfigMapGenerator, f.SecretGenerator) {
+		for _, file := range generator.Files {
+			// Files may be given a key in the generated object with a 'key=' prefix.
+			if _, after, ok := strings.Cut(file, "="); ok {
+				file = after
+			}
+			refs = append(refs, file)
+		}
+		refs = append(refs, generator.Envs...)
+		refs = append(refs, generator.Env)
+	}
+	refs = append(refs, f.OpenAPI.Path)
+	return slices.DeleteFunc(refs, func(ref string) bool {
+		return ref == "" || strings.Contains(ref, "\n")
+	})
+}
@@ -0,0 +1,252 @@
+package core
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func Test_cachedRender(t *testing.T) {
+	oldConfigDir := configDir
+	configDir = t.TempDir()
+	t.Cleanup(func() { configDir = oldConfigDir })
+	calls := make([]bool, 0)
+	render := func(opts RenderOptions) (*Render, error) {
+		calls = append(calls, opts.DryRun)
+		r := &Render{AppName: "app", SrcName: "src", SrcType: "release", CmdLine: "helm template src"}
+		if !opts.DryRun {
+			r.Stdout = []byte("kind: ConfigMap")
+		}
+		return r, nil
+	}
+	key := func() (string, error) { return "key", nil }
+
+	// The first render misses the cache and the second hits it, rendering only its command line.
+	for i := 0; i < 2; i++ {
+		got, err := cachedRender(RenderOptions{}, key, render)
+		if err != nil {
+			t.Fatalf("cachedRender() error = %v", err)
+		}
+		if got.Doc() != "kind: ConfigMap" || got.CmdLine != "helm template src" {
+			t.Errorf("cachedRender() got = %v", got)
+		}
+	}
+	// Disabling the cache always renders.
+	if _, err := cachedRender(RenderOptions{NoCache: true}, key, render); err != nil {
+		t.Fatalf("cachedRender() error = %v", err)
+	}
+	if want := []bool{false, true, false}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("cachedRender() render calls with dry-run = %v, want %v", calls, want)
+	}
+
+	// Pruning without a maximum age removes all cached renders.
+	removed, err := PruneCache(0)
+	if err != nil || removed != 1 {
+		t.Errorf("PruneCache() = %v, %v, want 1, nil", removed, err)
+	}
+}
+
+func Test_getReleaseCacheKey(t *testing.T) {
+	oldConfigDir := configDir
+	configDir = path.Join("..", "testdata")
+	t.Cleanup(func() { configDir = oldConfigDir })
+	tests := []struct {
+		name    string
+		release Release
+		wantKey bool
+	}{
+		{
+			name:    "should not cache remote charts without a version",
+			release: Release{Name: "cert-manager", Chart: "jetstack/cert-manager"},
+			wantKey: false,
+		},
+		{
+			name:    "should not cache remote charts with a range of versions",
+			release: Release{Name: "cert-manager", Chart: "jetstack/cert-manager", Version: "^1.14.0"},
+			wantKey: false,
+		},
+		{
+			name:    "should cache local charts without a version",
+			release: Release{Name: "hello", Chart: "charts/hello", Engine: engineBuiltin},
+			wantKey: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getReleaseCacheKey("app", tt.release, "", RenderOptions{})
+			if err != nil {
+				t.Fatalf("getReleaseCacheKey() error = %v", err)
+			}
+			if (got != "") != tt.wantKey {
+				t.Errorf("getReleaseCacheKey() got = %q, want key %v", got, tt.wantKey)
+			}
+		})
+	}
+}
+
+func Test_hasHelmfileUnpinnedCharts(t *testing.T) {
+	tests := []struct {
+		name     string
+		helmfile string
+		files    map[string]string
+		want     bool
+	}{
+		{
+			name: "should find no unpinned charts in releases of pinned remote and local charts",
+			helmfile: `releases:
+- name: cert-manager
+  chart: jetstack/cert-manager
+  values:
+  - values.yaml
+  version: v1.14.4
+- name: web
+  chart: ./charts/web
+`,
+			want: false,
+		},
+		{
+			name: "should find no unpinned charts in releases with templated versions",
+			helmfile: `releases:
+- name: cert-manager
+  chart: {{ .Values.chart }}
+  version: {{ .Values.version }}
+`,
+			want: false,
+		},
+		{
+			name: "should find releases of remote charts without a version",
+			helmfile: `repositories:
+- name: jetstack
+  url: https://charts.jetstack.io
+releases:
+- name: cert-manager
+  chart: jetstack/cert-manager
+  values:
+  - version: v1.14.4
+- name: web
+  chart: ./charts/web
+`,
+			want: true,
+		},
+		{
+			name: "should find releases of remote charts with a range of versions",
+			helmfile: `releases:
+  - name: cert-manager
+    chart: jetstack/cert-manager
+    version: ">=1.14.0"
+`,
+			want: true,
+		},
+		{
+			name: "should find releases of remote charts without a version in bases",
+			helmfile: `bases:
+- base.yaml
+`,
+			files: map[string]string{"base.yaml": "releases:\n- name: cert-manager\n  chart: jetstack/cert-manager\n"},
+			want:  true,
+		},
+		{
+			name: "should find remote included Helmfiles",
+			helmfile: `helmfiles:
+- path: git::https://github.com/example/helmfiles.git@helmfile.yaml?ref=main
+`,
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, data := range tt.files {
+				if err := os.WriteFile(path.Join(dir, name), []byte(data), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			helmfile := path.Join(dir, "helmfile.yaml")
+			if err := os.WriteFile(helmfile, []byte(tt.helmfile), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := hasHelmfileUnpinnedCharts(helmfile)
+			if err != nil {
+				t.Fatalf("hasHelmfileUnpinnedCharts() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("hasHelmfileUnpinnedCharts() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getKustomizationCacheKey(t *testing.T) {
+	oldConfigDir := configDir
+	configDir = t.TempDir()
+	t.Cleanup(func() { configDir = oldConfigDir })
+	files := map[string]string{
+		"common/settings.env": "GREETING=hello\n",
+		"local/kustomization.yaml": `resources:
+- configmap.yaml
+configMapGenerator:
+- name: settings
+  envs:
+  - ../common/settings.env
+`,
+		"local/configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: hello\n",
+		"remote/kustomization.yaml": `resources:
+- github.com/example/manifests/base?ref=main
+`,
+		"helm/kustomization.yaml": `helmCharts:
+- name: cert-manager
+  repo: https://charts.jetstack.io
+`,
+	}
+	for name, data := range files {
+		if err := os.MkdirAll(path.Join(configDir, path.Dir(name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path.Join(configDir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	getKey := func(source string) string {
+		key, err := getKustomizationCacheKey("app", Kustomization{Name: source, Source: source, Engine: engineBuiltin}, RenderOptions{})
+		if err != nil {
+			t.Fatalf("getKustomizationCacheKey() error = %v", err)
+		}
+		return key
+	}
+
+	// Kustomizations referencing remote resources or Helm charts are not cached.
+	for _, source := range []string{"remote", "helm"} {
+		if key := getKey(source); key != "" {
+			t.Errorf("getKustomizationCacheKey() got = %q for %s, want no key", key, source)
+		}
+	}
+
+	// Changing a file referenced outside of the kustomization directory changes the key.
+	key := getKey("local")
+	if key == "" {
+		t.Fatalf("getKustomizationCacheKey() got no key")
+	}
+	if err := os.WriteFile(path.Join(configDir, "common", "settings.env"), []byte("GREETING=bye\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := getKey("local"); got == key {
+		t.Errorf("getKustomizationCacheKey() got the same key after a referenced file changed")
+	}
+}
+
+func Test_getHelmfileReferencedFiles(t *testing.T) {
+	helmfile := path.Join("..", "testdata", "helmfile.yaml")
+	want := []string{
+		path.Join("..", "testdata", "renderfile.yaml"),
+		path.Join("..", "testdata", "config", "cert-manager.values.yaml.gotmpl"),
+		path.Join("..", "testdata", "config", "external-dns.values.yaml.gotmpl"),
+	}
+	got, err := getHelmfileReferencedFiles(helmfile)
+	if err != nil {
+		t.Fatalf("getHelmfileReferencedFiles() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getHelmfileReferencedFiles() got = %v, want %v", got, want)
+	}
+}
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -15,6 +17,8 @@ import (
 var configDir string
 
 // LoadConfig loads a YAML config file from the given path into a Config struct and returns it.
+// The config is validated before it is decoded, and all problems found are returned
+// together as ValidationErrors.
 func LoadConfig(filePath string) (*Config, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -30,10 +34,19 @@ func LoadConfig(filePath string) (*Config, error) {
 	// Cache the directory of the config file for resolving relative paths used elsewhere.
 	configDir = filepath.Dir(filePath)
 
-	// Decode the YAML config file into a Config and return it.
+	// Decode the YAML config file into a node tree and validate it.
 	decoder := yaml.NewDecoder(file)
+	root := yaml.Node{}
+	if err := decoder.Decode(&root); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode YAML from config: %w", err)
+	}
+	if errs := validateConfigNode(filePath, &root); len(errs) > 0 {
+		return nil, errs
+	}
+
+	// Decode the validated node tree into a Config and return it.
 	config := Config{}
-	if err := decoder.Decode(&config); err != nil {
+	if err := root.Decode(&config); err != nil {
 		return nil, fmt.Errorf("failed to decode YAML from config: %w", err)
 	}
 	config.Path = filePath
@@ -94,20 +107,315 @@ func (c *Config) MissingApps(appNames []string) []string {
 	return missing
 }
 
+// OutputPathTemplates returns the output path templates of apps in the config by their names.
+// Apps without an output path template of their own use the one of the Renderfile, if any.
+func (c *Config) OutputPathTemplates() map[string]string {
+	templates := make(map[string]string)
+	for _, app := range c.Renderfile.Apps {
+		if tmpl := app.Output.pathTemplate(c.Renderfile.Output); tmpl != "" {
+			templates[app.Name] = tmpl
+		}
+	}
+	return templates
+}
+
+// OutputNormalizations returns the normalizations of the output files of apps in the config by their names.
+// Apps use the normalizations of the Renderfile as well as their own.
+func (c *Config) OutputNormalizations() map[string]Normalize {
+	normalizations := make(map[string]Normalize)
+	for _, app := range c.Renderfile.Apps {
+		if n := app.Output.Normalize.merge(c.Renderfile.Output.Normalize); !n.isEmpty() {
+			normalizations[app.Name] = n
+		}
+	}
+	return normalizations
+}
+
 // Renderfile represents the structure of the top-level '.renderfile' section of the config.
 type Renderfile struct {
-	Schema string `yaml:"schema"`
-	Apps   []App  `yaml:"apps"`
+	Schema       string        `yaml:"schema"`
+	Output       Output        `yaml:"output"`
+	GitOps       GitOps        `yaml:"gitops"`
+	Environments []Environment `yaml:"environments"`
+	Apps         []App         `yaml:"apps"`
+}
+
+// Environment represents the structure of an environment in the '.renderfile.environments' section of the
+// config, like a cluster the apps are deployed to. The manifests of an environment are rendered from the
+// config with the environment applied, as returned by Config.ForEnvironment.
+type Environment struct {
+	// Name is the name of the environment, available to sources as the '{env}' placeholder, and to execs as the
+	// MANIFESTUS_ENV environment variable.
+	Name string `yaml:"name"`
+
+	// Data are the values of placeholders in the sources of bundles and CRDs, replacing their data with the same keys.
+	Data map[string]string `yaml:"data"`
+
+	// Values are the paths to values files added to the values of releases with a chart, by the names of their
+	// apps and releases, so that they override values of the release.
+	Values map[string]map[string]StringList `yaml:"values"`
+
+	// Apps are the names of the apps enabled in the environment, replacing the 'disabled' field of apps if set.
+	Apps []string `yaml:"apps"`
+
+	// OutputDir is the output directory of the manifests of the environment, defaulting to a directory
+	// named after the environment in the output directory.
+	OutputDir string `yaml:"outputDir"`
+}
+
+// Output represents the structure of the '.manifestus.output' and '.manifestus.apps.*.output'
+// sections of the config, configuring the output files of rendered manifests.
+type Output struct {
+	// PathTemplate is the template of the paths of output files relative to the output directory,
+	// overriding the layout of output files. See PathTemplateVars for the variables it may use.
+	PathTemplate string `yaml:"pathTemplate"`
+
+	// Normalize normalizes the rendered manifests in output files, so that renders of the same inputs
+	// always produce the same output files.
+	Normalize Normalize `yaml:"normalize"`
+}
+
+// Normalize represents the 'normalize' field of the output sections of the config. Normalizations of the
+// Renderfile apply to all apps, which may enable more normalizations of their own.
+type Normalize struct {
+	// SortDocuments sorts the documents of manifests by the kind, namespace and name of their objects.
+	SortDocuments bool `yaml:"sortDocuments"`
+
+	// SortKeys sorts the keys of mappings in documents, and re-encodes them with an indentation of two spaces.
+	SortKeys bool `yaml:"sortKeys"`
+
+	// StripSourceComments removes the '# Source:' comments generated by Helm from documents.
+	StripSourceComments bool `yaml:"stripSourceComments"`
+
+	// TrimTrailingWhitespace removes whitespace from the ends of lines.
+	TrimTrailingWhitespace bool `yaml:"trimTrailingWhitespace"`
+
+	// FinalNewline ends output files with exactly one newline.
+	FinalNewline bool `yaml:"finalNewline"`
+}
+
+// pathTemplate returns the output path template, or the one of the parent output if not set.
+func (o Output) pathTemplate(parent Output) string {
+	if o.PathTemplate != "" {
+		return o.PathTemplate
+	}
+	return parent.PathTemplate
 }
 
 // App represents the structure of an app in '.manifestus.apps' section of the config.
+// Sources of types rendered by renderers registered outside this package are kept
+// undecoded in Extensions by their keys, and decoded by their renderers.
 type App struct {
-	Name           string          `yaml:"name"`
-	Disabled       bool            `yaml:"disabled"`
-	Releases       []Release       `yaml:"releases"`
-	Kustomizations []Kustomization `yaml:"kustomizations"`
-	Bundles        []Bundle        `yaml:"bundles"`
-	CRDs           []CRDs          `yaml:"crds"`
+	Name           string               `yaml:"name"`
+	Disabled       bool                 `yaml:"disabled"`
+	Output         Output               `yaml:"output"`
+	GitOps         GitOps               `yaml:"gitops"`
+	Transform      Transform            `yaml:"transform"`
+	Releases       []Release            `yaml:"releases"`
+	Kustomizations []Kustomization      `yaml:"kustomizations"`
+	Bundles        []Bundle             `yaml:"bundles"`
+	CRDs           []CRDs               `yaml:"crds"`
+	Jsonnets       []Jsonnet            `yaml:"jsonnets"`
+	Cues           []Cue                `yaml:"cues"`
+	Execs          []Exec               `yaml:"execs"`
+	Extensions     map[string]yaml.Node `yaml:",inline"`
+}
+
+// GitOps represents the structure of the '.manifestus.gitops' and '.manifestus.apps.*.gitops' sections of the
+// config, configuring the Argo CD Application or Flux Kustomization objects deploying the output files of apps.
+// Fields of apps override the fields of the Renderfile.
+type GitOps struct {
+	// Tool is the GitOps tool deploying apps, either 'argocd' or 'flux'. No objects are written if empty.
+	Tool string `yaml:"tool"`
+
+	// Dir is the directory the objects are written to, relative to the output directory, defaulting to 'gitops'.
+	Dir string `yaml:"dir"`
+
+	// Namespace is the namespace of the objects, defaulting to 'argocd' or 'flux-system'.
+	Namespace string `yaml:"namespace"`
+
+	// Path is the path of the output directory in the Git repository, defaulting to the output directory.
+	Path string `yaml:"path"`
+
+	// RepoURL is the URL of the Git repository of Argo CD Applications.
+	RepoURL string `yaml:"repoURL"`
+
+	// TargetRevision is the revision of the Git repository of Argo CD Applications, defaulting to 'HEAD'.
+	TargetRevision string `yaml:"targetRevision"`
+
+	// Project is the project of Argo CD Applications, defaulting to 'default'.
+	Project string `yaml:"project"`
+
+	// SourceRef is the name of the GitRepository of Flux Kustomizations, defaulting to 'flux-system'.
+	SourceRef string `yaml:"sourceRef"`
+
+	// Destination is the cluster and namespace apps are deployed to.
+	Destination GitOpsDestination `yaml:"destination"`
+
+	// SyncPolicy is the sync policy of Argo CD Applications.
+	SyncPolicy GitOpsSyncPolicy `yaml:"syncPolicy"`
+
+	// Interval is the reconciliation interval of Flux Kustomizations, defaulting to '10m'.
+	Interval string `yaml:"interval"`
+
+	// Prune deletes objects no longer in the output files of apps, if automated syncs are enabled in Argo CD.
+	Prune *bool `yaml:"prune"`
+}
+
+// Transform represents the structure of the 'transform' sections of apps and their sources in the config,
+// transforming objects rendered before they are written. Transforms of sources are applied after the
+// transform of their app: their namespace and labels and annotations with the same keys take precedence.
+type Transform struct {
+	// Namespace is set on namespaced objects without a namespace.
+	Namespace string `yaml:"namespace"`
+
+	// Labels are added to the labels of all objects, replacing labels with the same keys.
+	Labels map[string]string `yaml:"labels"`
+
+	// Annotations are added to the annotations of all objects, replacing annotations with the same keys.
+	Annotations map[string]string `yaml:"annotations"`
+
+	// Strip are JSON Pointers of fields removed from all objects, whose segments may be '*' to match any key or index.
+	Strip []string `yaml:"strip"`
+
+	// ClusterScopedKinds are kinds of cluster-scoped objects the namespace is not set on, in addition to
+	// the cluster-scoped kinds of Kubernetes and of cluster-scoped custom resources defined in the same render.
+	ClusterScopedKinds []string `yaml:"clusterScopedKinds"`
+}
+
+// SourceRules represents the fields of sources in the config processing the objects rendered from them,
+// which are the same for all source types.
+type SourceRules struct {
+	// Transform is the transform of objects rendered from the source, applied after the transform of its app.
+	Transform Transform `yaml:"transform"`
+
+	Filters            `yaml:",inline"`
+	SecretGuard        `yaml:",inline"`
+	LintSuppressions   `yaml:",inline"`
+	IgnoredDifferences `yaml:",inline"`
+}
+
+// SrcTransform returns the transform of objects rendered from a source.
+func (r SourceRules) SrcTransform() Transform {
+	return r.Transform
+}
+
+// Filters represents the 'include' and 'exclude' fields of sources in the config, filtering objects rendered
+// before they are transformed. If there are include rules, only objects matching any of them are kept, and
+// objects matching any exclude rules are removed.
+type Filters struct {
+	// Include are the rules of objects kept.
+	Include []ObjectFilter `yaml:"include"`
+
+	// Exclude are the rules of objects removed.
+	Exclude []ObjectFilter `yaml:"exclude"`
+}
+
+// SrcFilters returns the rules including and excluding objects rendered from a source.
+func (f Filters) SrcFilters() Filters {
+	return f
+}
+
+// SecretGuard represents the 'allowSecrets' field of sources in the config, allowing objects rendered with
+// plaintext secrets to be written to output files.
+type SecretGuard struct {
+	// AllowSecrets are the rules of objects allowed to have plaintext secrets.
+	AllowSecrets []ObjectFilter `yaml:"allowSecrets"`
+}
+
+// SrcAllowSecrets returns the rules of objects rendered from a source allowed to have plaintext secrets.
+func (g SecretGuard) SrcAllowSecrets() []ObjectFilter {
+	return g.AllowSecrets
+}
+
+// LintSuppressions represents the 'suppressLint' field of sources in the config, suppressing lint rules for
+// objects rendered from them.
+type LintSuppressions struct {
+	// SuppressLint are the lint rules suppressed and the objects they are suppressed for.
+	SuppressLint []LintSuppression `yaml:"suppressLint"`
+}
+
+// SrcSuppressLint returns the lint rules suppressed for objects rendered from a source.
+func (s LintSuppressions) SrcSuppressLint() []LintSuppression {
+	return s.SuppressLint
+}
+
+// LintSuppression represents an item of the 'suppressLint' field of sources in the config. The lint rules
+// are suppressed for objects matching the rule filtering objects, which matches all objects if empty.
+type LintSuppression struct {
+	// Rules are the IDs of the lint rules suppressed, or all rules if empty.
+	Rules []string `yaml:"rules"`
+
+	// ObjectFilter matches the objects the lint rules are suppressed for.
+	ObjectFilter `yaml:",inline"`
+}
+
+// IgnoredDifferences represents the 'ignoreDifferences' field of sources in the config, ignoring differences
+// of fields of objects rendered from them with different values in every render, like random passwords.
+type IgnoredDifferences struct {
+	// IgnoreDifferences are the fields ignored and the objects they are ignored for.
+	IgnoreDifferences []IgnoreDifference `yaml:"ignoreDifferences"`
+}
+
+// SrcIgnoreDifferences returns the fields of objects rendered from a source whose differences are ignored.
+func (d IgnoredDifferences) SrcIgnoreDifferences() []IgnoreDifference {
+	return d.IgnoreDifferences
+}
+
+// IgnoreDifference represents an item of the 'ignoreDifferences' field of sources in the config. Differences
+// of the fields are ignored for objects matching the rule filtering objects, which matches all objects if empty.
+type IgnoreDifference struct {
+	// JSONPointers are the JSON Pointers of the fields ignored, whose '*' segments match any key or index.
+	JSONPointers []string `yaml:"jsonPointers"`
+
+	// ObjectFilter matches the objects the fields are ignored for.
+	ObjectFilter `yaml:",inline"`
+}
+
+// ObjectFilter represents a rule matching objects in the 'include' and 'exclude' fields of sources in the config.
+// Objects match if they match all fields set, whose values are globs where '*' matches any characters.
+type ObjectFilter struct {
+	// APIVersion matches the API version of objects, like 'batch/v1' or 'cert-manager.io/*'.
+	APIVersion string `yaml:"apiVersion"`
+
+	// Kind matches the kind of objects.
+	Kind string `yaml:"kind"`
+
+	// Name matches the name of objects.
+	Name string `yaml:"name"`
+
+	// Namespace matches the namespace of objects, which is empty for objects without a namespace.
+	Namespace string `yaml:"namespace"`
+
+	// Labels match labels of objects by their keys.
+	Labels map[string]string `yaml:"labels"`
+
+	// Annotations match annotations of objects by their keys, like 'helm.sh/hook: "*"'.
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// GitOpsDestination represents the cluster and namespace apps are deployed to.
+type GitOpsDestination struct {
+	// Server is the API server URL of the cluster of Argo CD Applications, defaulting to the in-cluster server.
+	Server string `yaml:"server"`
+
+	// Name is the name of the cluster of Argo CD Applications, used instead of the server.
+	Name string `yaml:"name"`
+
+	// Namespace is the namespace objects without one are deployed to.
+	Namespace string `yaml:"namespace"`
+}
+
+// GitOpsSyncPolicy represents the sync policy of Argo CD Applications.
+type GitOpsSyncPolicy struct {
+	// Automated enables automated syncs.
+	Automated *bool `yaml:"automated"`
+
+	// SelfHeal reverts changes made in the cluster during automated syncs.
+	SelfHeal *bool `yaml:"selfHeal"`
+
+	// SyncOptions are the options of syncs, like 'CreateNamespace=true'.
+	SyncOptions []string `yaml:"syncOptions"`
 }
 
 // Release represents the structure of a Helm chart release in '.manifestus.apps.*.releases' section of the config.
@@ -119,7 +427,8 @@ type App struct {
 //
 // If 'chart' is provided, the release will be rendered with 'helm template' command using the
 // Helm chart in the 'chart' field at 'version' level into the namespace in the 'namespace' field
-// with the 'values' file in the 'values' field.
+// with the 'values' files in the 'values' field. If 'engine' is 'builtin', the chart must be a
+// local chart directory or packaged chart, and it is rendered in-process with the Helm SDK instead.
 type Release struct {
 	// Name is the Helm release name.
 	Name string `yaml:"name"`
@@ -136,21 +445,172 @@ type Release struct {
 	// Version is the Helm chart version used to render the release with 'helm template' command.
 	Version string `yaml:"version"`
 
-	// Values is the path to the values file used to render the release with 'helm template' command.
-	Values string `yaml:"values"`
+	// Values are the paths to the values files used to render the release with 'helm template' command.
+	// A single path may be given instead of a list.
+	Values StringList `yaml:"values"`
+
+	// KubeVersion is the Kubernetes version used for capabilities when rendering the release with a chart.
+	KubeVersion string `yaml:"kubeVersion"`
+
+	// Engine is the engine rendering the release with a chart, either 'binary' (the default) to
+	// execute the 'helm' binary, or 'builtin' to render a local chart in-process with the Helm SDK.
+	Engine string `yaml:"engine"`
+
+	SourceRules `yaml:",inline"`
+}
+
+// SrcName returns the name of the release.
+func (r Release) SrcName() string {
+	return r.Name
+}
+
+// StringList is a list of strings that may also be given as a single string in the config.
+type StringList []string
+
+// UnmarshalYAML decodes a StringList from either a single string or a sequence of strings.
+func (l *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = StringList{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
 }
 
 // Kustomization represents the structure of a kustomization in '.manifestus.apps.*.kustomizations' section of the config.
+//
+// The kustomization will be rendered with 'kustomize build' command using the kustomization in the 'source'
+// field. If 'engine' is 'builtin', the source must be a local kustomization, and it is built in-process
+// with the kustomize API instead.
 type Kustomization struct {
-	Name   string `yaml:"name"`
+	// Name is the name of the kustomization.
+	Name string `yaml:"name"`
+
+	// Source is the local path or remote URL of the kustomization.
 	Source string `yaml:"source"`
+
+	// LoadRestrictor restricts the files the kustomization can load, 'LoadRestrictionsRootOnly' by default
+	// or 'LoadRestrictionsNone', like the '--load-restrictor' flag of 'kustomize build'.
+	LoadRestrictor string `yaml:"loadRestrictor"`
+
+	// EnableHelm enables the Helm chart inflation generator, like the '--enable-helm' flag of 'kustomize build'.
+	EnableHelm bool `yaml:"enableHelm"`
+
+	// EnableAlphaPlugins enables kustomize plugins, like the '--enable-alpha-plugins' flag of 'kustomize build'.
+	EnableAlphaPlugins bool `yaml:"enableAlphaPlugins"`
+
+	// Components are paths to kustomize components added to the build of the kustomization.
+	// They require the 'builtin' engine, as 'kustomize build' has no flag to add them.
+	Components []string `yaml:"components"`
+
+	// Engine is the engine building the kustomization, 'binary' by default or 'builtin'.
+	Engine string `yaml:"engine"`
+
+	SourceRules `yaml:",inline"`
+}
+
+// SrcName returns the name of the kustomization.
+func (k Kustomization) SrcName() string {
+	return k.Name
+}
+
+// Jsonnet represents the structure of a Jsonnet program in '.manifestus.apps.*.jsonnets' section of the config.
+//
+// The program in the 'main' file is evaluated in-process with go-jsonnet, and must evaluate to a
+// Kubernetes object, or to arrays and objects nesting Kubernetes objects, which are rendered in order.
+type Jsonnet struct {
+	// Name is the name of the Jsonnet program.
+	Name string `yaml:"name"`
+
+	// Main is the path to the main Jsonnet file evaluated.
+	Main string `yaml:"main"`
+
+	// JPath are the library search directories for imports, later directories taking precedence.
+	JPath []string `yaml:"jpath"`
+
+	// ExtVars are the external string variables available to the program with 'std.extVar'.
+	ExtVars map[string]string `yaml:"extVars"`
+
+	// TLAs are the string top-level arguments passed to the program if it evaluates to a function.
+	TLAs map[string]string `yaml:"tlas"`
+
+	SourceRules `yaml:",inline"`
+}
+
+// SrcName returns the name of the Jsonnet program.
+func (j Jsonnet) SrcName() string {
+	return j.Name
+}
+
+// Cue represents the structure of a CUE package in '.manifestus.apps.*.cues' section of the config.
+//
+// The package in the 'dir' directory is evaluated in-process with the CUE SDK, and its value, or the
+// value of the 'expression' evaluated in its scope, must be a Kubernetes object, or arrays and structs
+// nesting Kubernetes objects, which are rendered in order.
+type Cue struct {
+	// Name is the name of the CUE package source.
+	Name string `yaml:"name"`
+
+	// Dir is the path to the directory of the CUE package evaluated.
+	Dir string `yaml:"dir"`
+
+	// Package is the name of the package evaluated, if the directory contains more than one.
+	Package string `yaml:"package"`
+
+	// Tags are the values injected in '@tag' attributes of the package, as 'key=value' or 'key' for boolean tags.
+	Tags []string `yaml:"tags"`
+
+	// Expression is an optional expression evaluated in the scope of the package to select the objects rendered.
+	Expression string `yaml:"expression"`
+
+	SourceRules `yaml:",inline"`
+}
+
+// SrcName returns the name of the CUE package source.
+func (c Cue) SrcName() string {
+	return c.Name
+}
+
+// Exec represents the structure of a render command in '.manifestus.apps.*.execs' section of the config.
+//
+// The command in the 'command' field is executed without a shell in the 'dir' working directory, with
+// the 'env' environment variables added to those of manifestus, and its stdout is rendered as manifests.
+type Exec struct {
+	// Name is the name of the render command.
+	Name string `yaml:"name"`
+
+	// Command is the name and args of the command executed.
+	Command []string `yaml:"command"`
+
+	// Dir is the working directory of the command, defaulting to the directory of the config file.
+	Dir string `yaml:"dir"`
+
+	// Env are the environment variables of the command added to those of manifestus.
+	Env map[string]string `yaml:"env"`
+
+	SourceRules `yaml:",inline"`
+}
+
+// SrcName returns the name of the render command.
+func (e Exec) SrcName() string {
+	return e.Name
 }
 
 // Bundle represents the structure of the object in '.manifestus.apps.*.bundles' section of the config.
 type Bundle struct {
-	Name    string            `yaml:"name"`
-	Data    map[string]string `yaml:"data"`
-	Sources []string          `yaml:"sources"`
+	Name        string            `yaml:"name"`
+	Data        map[string]string `yaml:"data"`
+	Sources     []string          `yaml:"sources"`
+	SourceRules `yaml:",inline"`
+}
+
+// SrcName returns the name of the bundle.
+func (b Bundle) SrcName() string {
+	return b.Name
 }
 
 // Paths returns filesystem paths in a bundle with {placeholders} replaced by values from the bundle's data.
@@ -187,9 +647,15 @@ func (b Bundle) URLs() ([]string, error) {
 
 // CRDs represents the structure of the object in '.manifestus.apps.*.crds' section of the config.
 type CRDs struct {
-	Name    string            `yaml:"name"`
-	Data    map[string]string `yaml:"data"`
-	Sources []string          `yaml:"sources"`
+	Name        string            `yaml:"name"`
+	Data        map[string]string `yaml:"data"`
+	Sources     []string          `yaml:"sources"`
+	SourceRules `yaml:",inline"`
+}
+
+// SrcName returns the name of the CRDs.
+func (c CRDs) SrcName() string {
+	return c.Name
 }
 
 // Paths returns filesystem paths in a CRDs with {placeholders} replaced by values from the CRDs's data.
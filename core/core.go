@@ -16,9 +16,9 @@ func EnsureAppNamesExist(cfg *Config, appNames []string) error {
 	return nil
 }
 
-// EnsureSrcTypesValid checks if the given source types are valid.
+// EnsureSrcTypesValid checks if the given source types are valid types of registered renderers.
 func EnsureSrcTypesValid(srcTypes []string) error {
-	validSrcTypes := StringKeys(ValidSrcTypes)
+	validSrcTypes := SrcTypes()
 	for _, srcType := range srcTypes {
 		if !contains(validSrcTypes, srcType) {
 			return fmt.Errorf("invalid source type '%s'", srcType)
@@ -28,61 +28,47 @@ func EnsureSrcTypesValid(srcTypes []string) error {
 }
 
 // GetOutputFiles returns a list of output files of rendered manifests for named apps in the Config.
-func GetOutputFiles(cfg *Config, appNames, srcNames, srcTypes []string, flatten bool) []string {
+// In the resource layout, the output files depend on the objects rendered, so the directories of
+// the output files of sources are returned instead, with a trailing slash.
+func GetOutputFiles(cfg *Config, appNames, srcNames, srcTypes []string, opts OutputOptions) ([]string, error) {
 	paths := make([]string, 0)
 	for _, appName := range appNames {
 		app := cfg.FindApp(appName)
 		if app.Disabled {
 			continue
 		}
-		if contains(srcTypes, "release") {
-			for _, release := range app.Releases {
-				if len(srcNames) > 0 && !contains(srcNames, release.Name) {
-					continue
-				}
-				paths = append(paths, getOutputFilePath(app.Name, release.Name, "release", flatten))
+		for _, r := range Renderers() {
+			if !contains(srcTypes, r.Type()) {
+				continue
 			}
-		}
-		if contains(srcTypes, "kustomization") {
-			for _, kustomization := range app.Kustomizations {
-				if len(srcNames) > 0 && !contains(srcNames, kustomization.Name) {
-					continue
-				}
-				paths = append(paths, getOutputFilePath(app.Name, kustomization.Name, "kustomization", flatten))
+			srcs, err := r.Sources(app)
+			if err != nil {
+				return nil, err
 			}
-		}
-		if contains(srcTypes, "bundle") {
-			for _, bundle := range app.Bundles {
-				if len(srcNames) > 0 && !contains(srcNames, bundle.Name) {
+			for _, src := range srcs {
+				if len(srcNames) > 0 && !contains(srcNames, src.SrcName()) {
 					continue
 				}
-				paths = append(paths, getOutputFilePath(app.Name, bundle.Name, "bundle", flatten))
-			}
-		}
-		if contains(srcTypes, "crds") {
-			for _, crd := range app.CRDs {
-				if len(srcNames) > 0 && !contains(srcNames, crd.Name) {
-					continue
-				}
-				paths = append(paths, getOutputFilePath(app.Name, crd.Name, "crds", flatten))
+				paths = append(paths, getOutputPath(r, app.Name, src.SrcName(), opts))
 			}
 		}
 	}
-	return paths
+	return paths, nil
 }
 
 // GetRenders returns a list of rendered manifests for named apps in the Config.
-func GetRenders(cfg *Config, appNames, srcNames, srcTypes []string, debug, dryRun bool) ([]*Render, error) {
-	results := make([]*Render, 0)
+// Sources are rendered concurrently by up to opts.Jobs workers, but renders are
+// always returned in the same order as if they were rendered one at a time.
+func GetRenders(cfg *Config, appNames, srcNames, srcTypes []string, opts RenderOptions) ([]*Render, error) {
+	tasks := make([]renderTask, 0)
 	for _, appName := range appNames {
-		app := cfg.FindApp(appName)
-		renders, err := getRendersForApp(app, srcNames, srcTypes, debug, dryRun)
+		appTasks, err := getRenderTasksForApp(cfg.FindApp(appName), srcNames, srcTypes, opts)
 		if err != nil {
 			return nil, err
 		}
-		results = append(results, renders...)
+		tasks = append(tasks, appTasks...)
 	}
-	return results, nil
+	return runRenderTasks(tasks, opts.Jobs)
 }
 
 // GetManifests returns a list of manifests from a list of renders.
@@ -132,11 +118,8 @@ func GetManifests(renders []*Render) []*Manifest {
 }
 
 // getOutputFilePath returns the path of an output file of an app rendered manifest.
-func getOutputFilePath(appName, srcName, srcType string, flatten bool) string {
-	if !flatten {
-		return path.Join(appName, fmt.Sprintf("%s.%s.manifest.yaml", srcName, srcType))
-	}
-	return fmt.Sprintf("%s-%s.%s.manifest.yaml", appName, srcName, srcType)
+func getOutputFilePath(appName, srcName, srcType string) string {
+	return path.Join(appName, fmt.Sprintf("%s.%s.manifest.yaml", srcName, srcType))
 }
 
 // Chart represents metadata of a Helm chart defined in a Renderfile or Helmfile.
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// groupKind identifies the kind of custom resources by their API group and kind.
+type groupKind struct {
+	group string
+	kind  string
+}
+
+// crdDefinition is the definition of the kind of custom resources by a CRD.
+type crdDefinition struct {
+	// name is the name of the CRD.
+	name string
+
+	// versions are the versions of the custom resources, in order of the CRD.
+	versions []crdVersion
+}
+
+// crdVersion is a version of custom resources defined by a CRD.
+type crdVersion struct {
+	name   string
+	served bool
+
+	// schema is the schema of custom resources of the version, or nil if the CRD has none.
+	schema *gojsonschema.Schema
+}
+
+// getCRDDefinitions returns the definitions of the kinds of custom resources by the CRDs in a list of objects,
+// by their group and kind. Problems loading the schemas of CRDs are returned as problems of the CRDs.
+func getCRDDefinitions(objects []sourceObject) (map[groupKind]*crdDefinition, []ObjectProblem) {
+	definitions := make(map[groupKind]*crdDefinition)
+	problems := make([]ObjectProblem, 0)
+	for _, object := range objects {
+		if object.APIVersion != "apiextensions.k8s.io/v1" || object.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		_, spec := mappingValue(object.Node, "spec")
+		if spec == nil {
+			continue
+		}
+		_, names := mappingValue(spec, "names")
+		_, versions := mappingValue(spec, "versions")
+		if names == nil || versions == nil || versions.Kind != yaml.SequenceNode {
+			continue
+		}
+		definition := &crdDefinition{name: object.Name}
+		for _, version := range versions.Content {
+			v := crdVersion{name: scalarValue(version, "name"), served: scalarValue(version, "served") == "true"}
+			schema, err := getCRDVersionSchema(version)
+			if err != nil {
+				problems = append(problems, object.problem(fmt.Sprintf("invalid openAPIV3Schema of version '%s': %v", v.name, err)))
+			}
+			v.schema = schema
+			definition.versions = append(definition.versions, v)
+		}
+		definitions[groupKind{group: scalarValue(spec, "group"), kind: scalarValue(names, "kind")}] = definition
+	}
+	return definitions, problems
+}
+
+// getCRDVersionSchema returns the schema of custom resources of a version of a CRD, or nil if it has none.
+func getCRDVersionSchema(version *yaml.Node) (*gojsonschema.Schema, error) {
+	_, schema := mappingValue(version, "schema")
+	if schema == nil {
+		return nil, nil
+	}
+	_, openAPIV3Schema := mappingValue(schema, "openAPIV3Schema")
+	if openAPIV3Schema == nil {
+		return nil, nil
+	}
+	var value any
+	if err := openAPIV3Schema.Decode(&value); err != nil {
+		return nil, err
+	}
+	return gojsonschema.NewSchema(gojsonschema.NewGoLoader(toJSONSchema(value)))
+}
+
+// toJSONSchema converts an OpenAPI v3 schema to a JSON schema, whose types of nullable values include null.
+func toJSONSchema(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		converted := make(map[string]any, len(v))
+		for key, child := range v {
+			converted[key] = toJSONSchema(child)
+		}
+		if nullable, _ := v["nullable"].(bool); nullable {
+			if t, ok := v["type"].(string); ok {
+				converted["type"] = []any{t, "null"}
+			}
+		}
+		return converted
+	case []any:
+		converted := make([]any, len(v))
+		for i, child := range v {
+			converted[i] = toJSONSchema(child)
+		}
+		return converted
+	default:
+		return value
+	}
+}
+
+// validateCustomResource returns the messages of the problems of a custom resource not valid against the
+// schema of its version in its definition by a CRD.
+func validateCustomResource(object Object, definition *crdDefinition) ([]string, error) {
+	_, version := splitAPIVersion(object.APIVersion)
+	names := make([]string, 0, len(definition.versions))
+	for _, v := range definition.versions {
+		if v.served {
+			names = append(names, v.name)
+		}
+	}
+	for _, v := range definition.versions {
+		if v.name != version {
+			continue
+		}
+		if !v.served {
+			return []string{fmt.Sprintf("version '%s' is not served by CRD %s (served: %s)", version, definition.name, strings.Join(names, ", "))}, nil
+		}
+		if v.schema == nil {
+			return nil, nil
+		}
+		return validateAgainstSchema(object, v.schema)
+	}
+	return []string{fmt.Sprintf("version '%s' is not defined by CRD %s (served: %s)", version, definition.name, strings.Join(names, ", "))}, nil
+}
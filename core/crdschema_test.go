@@ -0,0 +1,115 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// certificateCRD is a CRD of cert-manager Certificate objects, serving only version v1.
+const certificateCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: certificates.cert-manager.io
+spec:
+  group: cert-manager.io
+  names:
+    kind: Certificate
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required: [secretName]
+            properties:
+              secretName:
+                type: string
+              duration:
+                type: string
+                nullable: true
+  - name: v1alpha2
+    served: false
+    storage: false
+`
+
+func TestValidateManifests_customResources(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdout string
+		want   []string
+	}{
+		{
+			name: "should validate custom resources against the schemas of CRDs in other manifests",
+			stdout: `apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: valid
+  namespace: web
+spec:
+  secretName: web-tls
+  duration: null
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: invalid
+  namespace: web
+spec:
+  duration: 2160h
+`,
+			want: []string{"cert-manager.io/v1 Certificate web/invalid of bundle 'web' of app 'web': spec: secretName is required"},
+		},
+		{
+			name: "should report versions not served or not defined by CRDs",
+			stdout: `apiVersion: cert-manager.io/v1alpha2
+kind: Certificate
+metadata:
+  name: old
+  namespace: web
+---
+apiVersion: cert-manager.io/v1beta1
+kind: Certificate
+metadata:
+  name: unknown
+  namespace: web
+`,
+			want: []string{
+				"cert-manager.io/v1alpha2 Certificate web/old of bundle 'web' of app 'web': version 'v1alpha2' is not served by CRD certificates.cert-manager.io (served: v1)",
+				"cert-manager.io/v1beta1 Certificate web/unknown of bundle 'web' of app 'web': version 'v1beta1' is not defined by CRD certificates.cert-manager.io (served: v1)",
+			},
+		},
+		{
+			name: "should not validate objects of kinds not defined by CRDs without a schema validator",
+			stdout: `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+`,
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifests := GetManifests([]*Render{
+				{AppName: "cert-manager", SrcName: "cert-manager", SrcType: "crds", Stdout: []byte(certificateCRD)},
+				{AppName: "web", SrcName: "web", SrcType: "bundle", Stdout: []byte(tt.stdout)},
+			})
+			problems, err := ValidateManifests(manifests, nil, false)
+			if err != nil {
+				t.Fatalf("ValidateManifests() error = %v", err)
+			}
+			got := make([]string, len(problems))
+			for i, problem := range problems {
+				got[i] = problem.Error()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateManifests() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
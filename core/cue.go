@@ -0,0 +1,93 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+)
+
+// getCueExportCmdline returns a 'cue export' command line equivalent to the evaluation of a Cue source.
+func getCueExportCmdline(src Cue) string {
+	dir := resolvePath(src.Dir)
+	if !path.IsAbs(dir) {
+		// Relative directories must start with a dot not to be taken as import paths by 'cue'.
+		dir = "./" + path.Clean(dir)
+	}
+	if src.Package != "" {
+		dir += ":" + src.Package
+	}
+	args := []string{"cue", "export", dir}
+	for _, tag := range src.Tags {
+		args = append(args, "-t", tag)
+	}
+	if src.Expression != "" {
+		args = append(args, "-e", src.Expression)
+	}
+	return formatCmdline(append(args, "--out", "yaml"))
+}
+
+// evaluateCue evaluates the package in the directory of a Cue source in-process with the CUE SDK,
+// and returns the Kubernetes objects its value, or the value of its expression, evaluates to as YAML manifests.
+func evaluateCue(src Cue) ([]byte, error) {
+	if src.Dir == "" {
+		return nil, fmt.Errorf("missing package directory")
+	}
+	arg := "."
+	if src.Package != "" {
+		arg += ":" + src.Package
+	}
+	insts := load.Instances([]string{arg}, &load.Config{Dir: resolvePath(src.Dir), Tags: src.Tags})
+	if len(insts) != 1 {
+		return nil, fmt.Errorf("expected one package, found %d", len(insts))
+	}
+	if err := insts[0].Err; err != nil {
+		return nil, cueError(err)
+	}
+	ctx := cuecontext.New()
+	value := ctx.BuildInstance(insts[0])
+	if err := value.Err(); err != nil {
+		return nil, cueError(err)
+	}
+	if src.Expression != "" {
+		value = ctx.CompileString(src.Expression, cue.Scope(value), cue.InferBuiltins(true))
+		if err := value.Err(); err != nil {
+			return nil, cueError(err)
+		}
+	}
+	if err := value.Validate(cue.Concrete(true)); err != nil {
+		return nil, cueError(err)
+	}
+	data, err := value.MarshalJSON()
+	if err != nil {
+		return nil, cueError(err)
+	}
+	return jsonToManifests(data)
+}
+
+// cueError returns an error with the details of all errors reported by CUE, which are otherwise summarized.
+func cueError(err error) error {
+	return fmt.Errorf("%s", strings.TrimSpace(errors.Details(err, nil)))
+}
+
+// renderCue renders an App Cue object.
+func renderCue(appName string, src Cue, opts RenderOptions) (*Render, error) {
+	render := &Render{
+		AppName: appName,
+		SrcName: src.Name,
+		SrcType: "cue",
+		CmdLine: getCueExportCmdline(src), // No command executed for CUE evaluated in-process. Diagnostic only.
+	}
+	if opts.DryRun {
+		return render, nil
+	}
+	render.Stdout, render.Err = evaluateCue(src)
+	if render.Err != nil {
+		render.Err = fmt.Errorf("cue evaluation failed: %w", render.Err)
+	}
+	return render, render.Err
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"testing"
+)
+
+func Test_evaluateCue(t *testing.T) {
+	configDir = "../testdata/cue"
+	t.Cleanup(func() { configDir = "" })
+	tests := []struct {
+		name    string
+		src     Cue
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "should evaluate with defaults",
+			src:  Cue{Name: "hello", Dir: "hello", Expression: "objects"},
+			want: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: hello-dev
+data:
+  message: Hello, World!
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hello-dev
+spec:
+  replicas: 1
+`,
+		},
+		{
+			name: "should evaluate with tags and expression",
+			src: Cue{
+				Name:       "hello",
+				Dir:        "hello",
+				Package:    "hello",
+				Tags:       []string{"env=prod", "replicas=3"},
+				Expression: "[objects.deployment]",
+			},
+			want: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hello-prod
+spec:
+  replicas: 3
+`,
+		},
+		{
+			name:    "should fail on values other than objects",
+			src:     Cue{Name: "hello", Dir: "hello"},
+			wantErr: true,
+		},
+		{
+			name:    "should fail on invalid tags",
+			src:     Cue{Name: "hello", Dir: "hello", Tags: []string{"replicas=many"}},
+			wantErr: true,
+		},
+		{
+			name:    "should fail on missing fields",
+			src:     Cue{Name: "hello", Dir: "hello", Expression: "objects.service"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluateCue(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("evaluateCue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("evaluateCue() got = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func Test_getCueExportCmdline(t *testing.T) {
+	configDir = "config"
+	t.Cleanup(func() { configDir = "" })
+	src := Cue{Dir: "hello", Package: "hello", Tags: []string{"env=prod"}, Expression: "objects"}
+	want := "cue export ./config/hello:hello -t env=prod -e objects --out yaml"
+	if got := getCueExportCmdline(src); got != want {
+		t.Errorf("getCueExportCmdline() got = %v, want %v", got, want)
+	}
+	src.Expression = `[for o in objects if o.metadata.name != 'web' {o}]`
+	want = `cue export ./config/hello:hello -t env=prod -e '[for o in objects if o.metadata.name != '\''web'\'' {o}]' --out yaml`
+	if got := getCueExportCmdline(src); got != want {
+		t.Errorf("getCueExportCmdline() got = %v, want %v", got, want)
+	}
+}
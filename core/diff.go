@@ -0,0 +1,308 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Statuses of files in the differences between two directories.
+const (
+	// FileAdded is the status of a file only in the new directory.
+	FileAdded = "added"
+
+	// FileRemoved is the status of a file only in the old directory.
+	FileRemoved = "removed"
+
+	// FileModified is the status of a file with different contents in both directories.
+	FileModified = "modified"
+)
+
+// diffContextLines is the number of unchanged lines around changes in unified diffs.
+const diffContextLines = 3
+
+// FileDiff describes the differences of a file between two directories.
+type FileDiff struct {
+	// Path is the path of the file relative to the directories.
+	Path string
+
+	// Status is the status of the file, one of FileAdded, FileRemoved or FileModified.
+	Status string
+
+	// Insertions is the number of lines inserted in the file.
+	Insertions int
+
+	// Deletions is the number of lines deleted from the file.
+	Deletions int
+
+	// Unified is the unified diff of a modified file.
+	Unified string
+}
+
+// DiffDirs compares the files in two directory trees, and returns the differences of files
+// added, removed or modified in the new directory, sorted by path. A missing directory is
+// compared as an empty one.
+func DiffDirs(oldDir, newDir string) ([]FileDiff, error) {
+	oldFiles, err := listFiles(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := listFiles(newDir)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(oldFiles)+len(newFiles))
+	for p := range oldFiles {
+		paths = append(paths, p)
+	}
+	for p := range newFiles {
+		if !oldFiles[p] {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	diffs := make([]FileDiff, 0)
+	for _, p := range paths {
+		var oldData, newData []byte
+		if oldFiles[p] {
+			if oldData, err = os.ReadFile(filepath.Join(oldDir, p)); err != nil {
+				return nil, err
+			}
+		}
+		if newFiles[p] {
+			if newData, err = os.ReadFile(filepath.Join(newDir, p)); err != nil {
+				return nil, err
+			}
+		}
+		if oldFiles[p] && newFiles[p] && bytes.Equal(oldData, newData) {
+			continue
+		}
+		edits := diffLines(splitLines(string(oldData)), splitLines(string(newData)))
+		diff := FileDiff{Path: p}
+		for _, e := range edits {
+			switch e.op {
+			case '+':
+				diff.Insertions++
+			case '-':
+				diff.Deletions++
+			}
+		}
+		switch {
+		case !oldFiles[p]:
+			diff.Status = FileAdded
+		case !newFiles[p]:
+			diff.Status = FileRemoved
+		default:
+			diff.Status = FileModified
+			diff.Unified = unifiedDiff("a/"+p, "b/"+p, edits, diffContextLines)
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// listFiles returns the set of paths of regular files in a directory tree relative to it.
+func listFiles(dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return files, nil
+	}
+	return files, err
+}
+
+// splitLines splits text into lines without their line endings.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lineEdit is an edit of a line in a diff, with op ' ' for unchanged, '-' for deleted and '+' for inserted lines.
+type lineEdit struct {
+	op   byte
+	line string
+}
+
+// diffLines returns the shortest edit script transforming lines a into lines b with the linear space
+// variant of the Myers diff algorithm, which splits both sequences at the middle snake of a shortest
+// edit script and diffs the parts before and after it recursively.
+func diffLines(a, b []string) []lineEdit {
+	return appendLineEdits(make([]lineEdit, 0, len(a)+len(b)), a, b)
+}
+
+// appendLineEdits appends the shortest edit script transforming lines a into lines b to edits.
+func appendLineEdits(edits []lineEdit, a, b []string) []lineEdit {
+	// Lines common to the start or end of both sequences are unchanged.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	for _, line := range a[:prefix] {
+		edits = append(edits, lineEdit{' ', line})
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			edits = append(edits, lineEdit{'+', line})
+		}
+	case len(b) == 0:
+		for _, line := range a {
+			edits = append(edits, lineEdit{'-', line})
+		}
+	default:
+		// Without common lines at their ends, both sequences need at least two edits, so the parts
+		// before and after the middle snake are both shorter to diff than the sequences.
+		x, y, u, v := middleSnake(a, b)
+		edits = appendLineEdits(edits, a[:x], b[:y])
+		for _, line := range a[x:u] {
+			edits = append(edits, lineEdit{' ', line})
+		}
+		edits = appendLineEdits(edits, a[u:], b[v:])
+	}
+
+	for _, line := range common {
+		edits = append(edits, lineEdit{' ', line})
+	}
+	return edits
+}
+
+// middleSnake returns the start (x, y) and end (u, v) of the middle snake of a shortest edit script
+// transforming lines a into lines b, a run of unchanged lines halfway through its edits. It searches
+// for shortest edit scripts from the start and from the end of both sequences at once until they
+// overlap, keeping only the furthest reaching x of each diagonal k of either search.
+func middleSnake(a, b []string) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	maxD := (n + m + 1) / 2
+	off := maxD + 1
+	// forward keeps the x of the start, and backward the distance from the end, at index k+off.
+	forward := make([]int, 2*off+1)
+	backward := make([]int, 2*off+1)
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && forward[off+k-1] < forward[off+k+1]) {
+				x = forward[off+k+1]
+			} else {
+				x = forward[off+k-1] + 1
+			}
+			y = x - k
+			u, v = x, y
+			for u < n && v < m && a[u] == b[v] {
+				u++
+				v++
+			}
+			forward[off+k] = u
+			// The diagonal k is the diagonal delta-k from the end.
+			if kb := delta - k; odd && kb >= -(d-1) && kb <= d-1 && u+backward[off+kb] >= n {
+				return x, y, u, v
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && backward[off+k-1] < backward[off+k+1]) {
+				u = backward[off+k+1]
+			} else {
+				u = backward[off+k-1] + 1
+			}
+			v = u - k
+			x, y = u, v
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			backward[off+k] = x
+			if kf := delta - k; !odd && kf >= -d && kf <= d && x+forward[off+kf] >= n {
+				return n - x, m - y, n - u, m - v
+			}
+		}
+	}
+	// The searches always overlap by the time each has made half of the edits of both sequences.
+	return 0, 0, n, m
+}
+
+// unifiedDiff returns a unified diff of line edits between two named files, with context lines around changes.
+func unifiedDiff(oldName, newName string, edits []lineEdit, context int) string {
+	var out strings.Builder
+	_, _ = fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	// Line numbers in the old and new files before each edit.
+	oldLines := make([]int, len(edits)+1)
+	newLines := make([]int, len(edits)+1)
+	for i, e := range edits {
+		oldLines[i+1], newLines[i+1] = oldLines[i], newLines[i]
+		if e.op != '+' {
+			oldLines[i+1]++
+		}
+		if e.op != '-' {
+			newLines[i+1]++
+		}
+	}
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+		// Extend the hunk until a run of unchanged lines longer than twice the context.
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(edits) && j-end <= 2*context; j++ {
+			if edits[j].op != ' ' {
+				end = j
+			}
+		}
+		end += context + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+		_, _ = fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(oldLines[start], oldLines[end]-oldLines[start]),
+			hunkRange(newLines[start], newLines[end]-newLines[start]))
+		for _, e := range edits[start:end] {
+			_, _ = fmt.Fprintf(&out, "%c%s\n", e.op, e.line)
+		}
+		i = end
+	}
+	return out.String()
+}
+
+// hunkRange formats the range of lines of a hunk in a file from the number of lines before it and its count.
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
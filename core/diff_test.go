@@ -0,0 +1,188 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func Test_unifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{
+			name: "should diff changed line with context",
+			old:  "a\nb\nc\nd\ne\nf\ng\nh\n",
+			new:  "a\nb\nc\nd\nE\nf\ng\nh\n",
+			want: `--- a/x
++++ b/x
+@@ -2,7 +2,7 @@
+ b
+ c
+ d
+-e
++E
+ f
+ g
+ h
+`,
+		},
+		{
+			name: "should split distant changes into hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			new:  "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			want: `--- a/x
++++ b/x
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -9,4 +10,3 @@
+ 9
+ 10
+ 11
+-12
+`,
+		},
+		{
+			name: "should diff from empty",
+			old:  "",
+			new:  "a\nb\n",
+			want: `--- a/x
++++ b/x
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := diffLines(splitLines(tt.old), splitLines(tt.new))
+			if got := unifiedDiff("a/x", "b/x", edits, 3); got != tt.want {
+				t.Errorf("unifiedDiff() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_diffLines(t *testing.T) {
+	// Edit scripts must transform the old lines into the new lines with the fewest changes, which is the
+	// number of lines not in a longest common subsequence of both.
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(40))
+		for i := range lines {
+			lines[i] = fmt.Sprint(rng.Intn(4))
+		}
+		return lines
+	}
+	for i := 0; i < 500; i++ {
+		a, b := randomLines(), randomLines()
+		edits := diffLines(a, b)
+		var oldLines, newLines []string
+		changes := 0
+		for _, e := range edits {
+			if e.op != '+' {
+				oldLines = append(oldLines, e.line)
+			}
+			if e.op != '-' {
+				newLines = append(newLines, e.line)
+			}
+			if e.op != ' ' {
+				changes++
+			}
+		}
+		if !slices.Equal(oldLines, a) || !slices.Equal(newLines, b) {
+			t.Fatalf("diffLines(%v, %v) = %v, does not transform the old lines into the new lines", a, b, edits)
+		}
+		if want := len(a) + len(b) - 2*longestCommonSubsequence(a, b); changes != want {
+			t.Fatalf("diffLines(%v, %v) = %v, got %d changes, want %d", a, b, edits, changes, want)
+		}
+	}
+
+	// Edit scripts of large rewritten files are computed in linear space.
+	a, b := make([]string, 3000), make([]string, 3000)
+	for i := range a {
+		a[i], b[i] = fmt.Sprintf("old %d", i), fmt.Sprintf("new %d", i)
+	}
+	if edits := diffLines(a, b); len(edits) != 6000 {
+		t.Errorf("diffLines() got %d edits, want 6000", len(edits))
+	}
+}
+
+// longestCommonSubsequence returns the length of a longest common subsequence of two sequences of lines.
+func longestCommonSubsequence(a, b []string) int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	return lengths[0][0]
+}
+
+func TestDiffDirs(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		filepath.Join(oldDir, "app", "same.yaml"):     "kind: Same\n",
+		filepath.Join(newDir, "app", "same.yaml"):     "kind: Same\n",
+		filepath.Join(oldDir, "app", "modified.yaml"): "kind: Old\nname: x\n",
+		filepath.Join(newDir, "app", "modified.yaml"): "kind: New\nname: x\n",
+		filepath.Join(oldDir, "app", "removed.yaml"):  "kind: Removed\n",
+		filepath.Join(newDir, "added.yaml"):           "kind: Added\nname: y\n",
+	}
+	for p, data := range files {
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := DiffDirs(oldDir, newDir)
+	if err != nil {
+		t.Fatalf("DiffDirs() error = %v", err)
+	}
+	want := []FileDiff{
+		{Path: "added.yaml", Status: FileAdded, Insertions: 2},
+		{Path: "app/modified.yaml", Status: FileModified, Insertions: 1, Deletions: 1, Unified: strings.Join([]string{
+			"--- a/app/modified.yaml",
+			"+++ b/app/modified.yaml",
+			"@@ -1,2 +1,2 @@",
+			"-kind: Old",
+			"+kind: New",
+			" name: x",
+			"",
+		}, "\n")},
+		{Path: "app/removed.yaml", Status: FileRemoved, Deletions: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffDirs() got = %v, want %v", got, want)
+	}
+
+	// A missing directory is compared as an empty one.
+	got, err = DiffDirs(filepath.Join(oldDir, "missing"), filepath.Join(oldDir, "app"))
+	if err != nil {
+		t.Fatalf("DiffDirs() error = %v", err)
+	}
+	if len(got) != 3 || got[0].Status != FileAdded {
+		t.Errorf("DiffDirs() got = %v", got)
+	}
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// envDataKey is the key of the data of bundles and CRDs whose value is the name of the environment.
+const envDataKey = "env"
+
+// envPlaceholder is the placeholder of the name of the environment in the paths and args of sources.
+const envPlaceholder = "{" + envDataKey + "}"
+
+// envExecVar is the environment variable of the commands of execs whose value is the name of the environment.
+const envExecVar = "MANIFESTUS_ENV"
+
+// EnvironmentNames returns the names of the environments in the config, in order of definition.
+func (c *Config) EnvironmentNames() []string {
+	names := make([]string, len(c.Renderfile.Environments))
+	for i, env := range c.Renderfile.Environments {
+		names[i] = env.Name
+	}
+	return names
+}
+
+// FindEnvironment returns the environment in the config with the given name.
+func (c *Config) FindEnvironment(envName string) *Environment {
+	for _, env := range c.Renderfile.Environments {
+		if env.Name == envName {
+			return &env
+		}
+	}
+	return nil
+}
+
+// ForEnvironment returns a copy of the config with the environment of the given name applied. Apps not in
+// the apps of the environment are disabled, if it has any, the data of the environment and its name are added
+// to the data of bundles and CRDs, and its values files are added to the values of releases. The '{env}'
+// placeholder in the paths and args of other sources is replaced with its name, which is also the value of
+// the MANIFESTUS_ENV environment variable of execs.
+func (c *Config) ForEnvironment(envName string) (*Config, error) {
+	env := c.FindEnvironment(envName)
+	if env == nil {
+		return nil, fmt.Errorf("environment '%s' not found in config (valid: %s)", envName, strings.Join(c.EnvironmentNames(), ", "))
+	}
+	data := maps.Clone(env.Data)
+	if data == nil {
+		data = make(map[string]string)
+	}
+	data[envDataKey] = env.Name
+	expand := strings.NewReplacer(envPlaceholder, env.Name).Replace
+
+	cfg := *c
+	cfg.Renderfile.Apps = make([]App, len(c.Renderfile.Apps))
+	for i, app := range c.Renderfile.Apps {
+		if len(env.Apps) > 0 {
+			app.Disabled = !slices.Contains(env.Apps, app.Name)
+		}
+		app.Releases = slices.Clone(app.Releases)
+		for j, release := range app.Releases {
+			release.Helmfile, release.Chart = expand(release.Helmfile), expand(release.Chart)
+			release.Values = slices.Concat(expandAll(release.Values, expand), env.Values[app.Name][release.Name])
+			app.Releases[j] = release
+		}
+		app.Kustomizations = slices.Clone(app.Kustomizations)
+		for j, kustomization := range app.Kustomizations {
+			kustomization.Source = expand(kustomization.Source)
+			kustomization.Components = expandAll(kustomization.Components, expand)
+			app.Kustomizations[j] = kustomization
+		}
+		app.Bundles = slices.Clone(app.Bundles)
+		for j, bundle := range app.Bundles {
+			app.Bundles[j].Data = mergeData(bundle.Data, data)
+		}
+		app.CRDs = slices.Clone(app.CRDs)
+		for j, crds := range app.CRDs {
+			app.CRDs[j].Data = mergeData(crds.Data, data)
+		}
+		app.Jsonnets = slices.Clone(app.Jsonnets)
+		for j, jsonnet := range app.Jsonnets {
+			jsonnet.Main = expand(jsonnet.Main)
+			jsonnet.JPath = expandAll(jsonnet.JPath, expand)
+			jsonnet.ExtVars = expandValues(jsonnet.ExtVars, expand)
+			jsonnet.TLAs = expandValues(jsonnet.TLAs, expand)
+			app.Jsonnets[j] = jsonnet
+		}
+		app.Cues = slices.Clone(app.Cues)
+		for j, cue := range app.Cues {
+			cue.Dir, cue.Expression = expand(cue.Dir), expand(cue.Expression)
+			cue.Tags = expandAll(cue.Tags, expand)
+			app.Cues[j] = cue
+		}
+		app.Execs = slices.Clone(app.Execs)
+		for j, exec := range app.Execs {
+			exec.Command, exec.Dir = expandAll(exec.Command, expand), expand(exec.Dir)
+			exec.Env = mergeData(expandValues(exec.Env, expand), map[string]string{envExecVar: env.Name})
+			app.Execs[j] = exec
+		}
+		cfg.Renderfile.Apps[i] = app
+	}
+	return &cfg, nil
+}
+
+// expandAll returns a copy of a list of strings with placeholders expanded, or nil if the list is.
+func expandAll(list []string, expand func(string) string) []string {
+	if list == nil {
+		return nil
+	}
+	expanded := make([]string, len(list))
+	for i, s := range list {
+		expanded[i] = expand(s)
+	}
+	return expanded
+}
+
+// expandValues returns a copy of a map of strings with placeholders in the values expanded, or nil if the map is.
+func expandValues(values map[string]string, expand func(string) string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	expanded := make(map[string]string, len(values))
+	for key, value := range values {
+		expanded[key] = expand(value)
+	}
+	return expanded
+}
+
+// EnvironmentOutputDir returns the output directory of the manifests of the environment of the given name,
+// which is a directory named after the environment in the output directory, unless it has one of its own.
+func (c *Config) EnvironmentOutputDir(envName, outputDir string) string {
+	if env := c.FindEnvironment(envName); env != nil && env.OutputDir != "" {
+		return env.OutputDir
+	}
+	return path.Join(outputDir, envName)
+}
+
+// EnvironmentTarget is an environment targeted by a command, with the config of the environment and the
+// output directory of its manifests.
+type EnvironmentTarget struct {
+	// Name is the name of the environment, empty if the config has no environments.
+	Name string
+
+	// Config is the config with the environment applied.
+	Config *Config
+
+	// OutputDir is the output directory of the manifests of the environment.
+	OutputDir string
+}
+
+// EnvironmentTargets returns the environments of the given names with their output directories in an output
+// directory, or all environments if no names are given. If the config has no environments and no names are
+// given, it returns the config itself, without an environment name, with the output directory. Environments
+// must not have output directories overlapping those of others, so the config and its environments never share
+// an output directory, and the output files of one are never mistaken for output files no longer rendered.
+func (c *Config) EnvironmentTargets(envNames []string, outputDir string) ([]EnvironmentTarget, error) {
+	if len(envNames) == 0 {
+		if len(c.Renderfile.Environments) == 0 {
+			return []EnvironmentTarget{{Config: c, OutputDir: outputDir}}, nil
+		}
+		envNames = c.EnvironmentNames()
+	}
+	targets := make([]EnvironmentTarget, len(envNames))
+	for i, envName := range envNames {
+		cfg, err := c.ForEnvironment(envName)
+		if err != nil {
+			return nil, err
+		}
+		targets[i] = EnvironmentTarget{Name: envName, Config: cfg, OutputDir: c.EnvironmentOutputDir(envName, outputDir)}
+	}
+	for _, target := range targets {
+		for _, env := range c.Renderfile.Environments {
+			envDir := c.EnvironmentOutputDir(env.Name, outputDir)
+			if env.Name != target.Name && (isInDir(target.OutputDir, envDir) || isInDir(envDir, target.OutputDir)) {
+				return nil, fmt.Errorf("output directory '%s' of environment '%s' overlaps output directory '%s' of environment '%s'", target.OutputDir, target.Name, envDir, env.Name)
+			}
+		}
+	}
+	return targets, nil
+}
+
+// isInDir returns true if a path is a directory or a path in it.
+func isInDir(p, dir string) bool {
+	p, dir = path.Clean(p), path.Clean(dir)
+	return p == dir || dir == "." || strings.HasPrefix(p, dir+"/")
+}
+
+// mergeData returns the data of a source with the data of an environment added, replacing values with
+// the same keys.
+func mergeData(data, envData map[string]string) map[string]string {
+	merged := maps.Clone(data)
+	if merged == nil {
+		merged = make(map[string]string, len(envData))
+	}
+	maps.Copy(merged, envData)
+	return merged
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfig_ForEnvironment(t *testing.T) {
+	cfg := &Config{Renderfile: Renderfile{
+		Environments: []Environment{
+			{
+				Name:   "prod",
+				Data:   map[string]string{"region": "eu-west-1"},
+				Values: map[string]map[string]StringList{"web": {"web": {"values/prod.yaml"}}},
+				Apps:   []string{"web"},
+			},
+			{Name: "dev"},
+		},
+		Apps: []App{
+			{
+				Name:     "web",
+				Releases: []Release{{Name: "web", Chart: "charts/web", Values: StringList{"values/web.yaml"}}},
+				Bundles:  []Bundle{{Name: "web", Data: map[string]string{"region": "us-east-1", "tier": "web"}, Sources: []string{"{env}/{region}/{tier}.yaml"}}},
+			},
+			{
+				Name:     "debug",
+				CRDs:     []CRDs{{Name: "debug", Sources: []string{"crds/{env}.yaml"}}},
+				Jsonnets: []Jsonnet{{Name: "debug", Main: "jsonnet/{env}.jsonnet", ExtVars: map[string]string{"env": "{env}"}}},
+				Execs:    []Exec{{Name: "debug", Command: []string{"./render.sh", "--env={env}"}}},
+			},
+		},
+	}}
+	tests := []struct {
+		name         string
+		envName      string
+		wantApps     []string
+		wantValues   []string
+		wantBundle   []string
+		wantCRDs     []string
+		wantJsonnet  Jsonnet
+		wantExec     Exec
+		wantErrorMsg string
+	}{
+		{
+			name:        "should apply apps, values and data of an environment",
+			envName:     "prod",
+			wantApps:    []string{"web"},
+			wantValues:  []string{"values/web.yaml", "values/prod.yaml"},
+			wantBundle:  []string{"prod/eu-west-1/web.yaml"},
+			wantCRDs:    []string{"crds/prod.yaml"},
+			wantJsonnet: Jsonnet{Name: "debug", Main: "jsonnet/prod.jsonnet", ExtVars: map[string]string{"env": "prod"}},
+			wantExec:    Exec{Name: "debug", Command: []string{"./render.sh", "--env=prod"}, Env: map[string]string{"MANIFESTUS_ENV": "prod"}},
+		},
+		{
+			name:        "should keep apps, values and data of sources not set by an environment",
+			envName:     "dev",
+			wantApps:    []string{"debug", "web"},
+			wantValues:  []string{"values/web.yaml"},
+			wantBundle:  []string{"dev/us-east-1/web.yaml"},
+			wantCRDs:    []string{"crds/dev.yaml"},
+			wantJsonnet: Jsonnet{Name: "debug", Main: "jsonnet/dev.jsonnet", ExtVars: map[string]string{"env": "dev"}},
+			wantExec:    Exec{Name: "debug", Command: []string{"./render.sh", "--env=dev"}, Env: map[string]string{"MANIFESTUS_ENV": "dev"}},
+		},
+		{
+			name:         "should fail on unknown environments",
+			envName:      "staging",
+			wantErrorMsg: "environment 'staging' not found in config (valid: prod, dev)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cfg.ForEnvironment(tt.envName)
+			if tt.wantErrorMsg != "" {
+				if err == nil || err.Error() != tt.wantErrorMsg {
+					t.Fatalf("ForEnvironment() error = %v, want %q", err, tt.wantErrorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ForEnvironment() error = %v", err)
+			}
+			if apps := got.EnabledAppNames(); !reflect.DeepEqual(apps, tt.wantApps) {
+				t.Errorf("ForEnvironment() apps = %v, want %v", apps, tt.wantApps)
+			}
+			web := got.FindApp("web")
+			if values := []string(web.Releases[0].Values); !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("ForEnvironment() values = %v, want %v", values, tt.wantValues)
+			}
+			if paths, err := web.Bundles[0].Paths(); err != nil || !reflect.DeepEqual(paths, tt.wantBundle) {
+				t.Errorf("ForEnvironment() bundle paths = %v, %v, want %v", paths, err, tt.wantBundle)
+			}
+			if paths, err := got.FindApp("debug").CRDs[0].Paths(); err != nil || !reflect.DeepEqual(paths, tt.wantCRDs) {
+				t.Errorf("ForEnvironment() CRDs paths = %v, %v, want %v", paths, err, tt.wantCRDs)
+			}
+			if jsonnet := got.FindApp("debug").Jsonnets[0]; !reflect.DeepEqual(jsonnet, tt.wantJsonnet) {
+				t.Errorf("ForEnvironment() jsonnet = %+v, want %+v", jsonnet, tt.wantJsonnet)
+			}
+			if exec := got.FindApp("debug").Execs[0]; !reflect.DeepEqual(exec, tt.wantExec) {
+				t.Errorf("ForEnvironment() exec = %+v, want %+v", exec, tt.wantExec)
+			}
+		})
+	}
+
+	// The config itself must be left unchanged.
+	if values := cfg.Renderfile.Apps[0].Releases[0].Values; len(values) != 1 {
+		t.Errorf("ForEnvironment() changed values of config to %v", values)
+	}
+	if data := cfg.Renderfile.Apps[0].Bundles[0].Data; data["region"] != "us-east-1" || data["env"] != "" {
+		t.Errorf("ForEnvironment() changed data of config to %v", data)
+	}
+	if exec := cfg.Renderfile.Apps[1].Execs[0]; exec.Command[1] != "--env={env}" || exec.Env != nil {
+		t.Errorf("ForEnvironment() changed exec of config to %+v", exec)
+	}
+}
+
+func TestConfig_EnvironmentOutputDir(t *testing.T) {
+	cfg := &Config{Renderfile: Renderfile{Environments: []Environment{
+		{Name: "prod", OutputDir: "clusters/prod"},
+		{Name: "dev"},
+	}}}
+	if got := cfg.EnvironmentOutputDir("prod", "manifests"); got != "clusters/prod" {
+		t.Errorf("EnvironmentOutputDir() got = %s, want clusters/prod", got)
+	}
+	if got := cfg.EnvironmentOutputDir("dev", "manifests"); got != "manifests/dev" {
+		t.Errorf("EnvironmentOutputDir() got = %s, want manifests/dev", got)
+	}
+}
+
+func TestConfig_EnvironmentTargets(t *testing.T) {
+	cfg := &Config{Renderfile: Renderfile{
+		Environments: []Environment{
+			{Name: "prod", OutputDir: "clusters/prod"},
+			{Name: "dev"},
+		},
+		Apps: []App{{Name: "web"}},
+	}}
+	tests := []struct {
+		name          string
+		cfg           *Config
+		envNames      []string
+		wantNames     []string
+		wantOutputDir []string
+		wantErrorMsg  string
+	}{
+		{
+			name:          "should target all environments if none are named",
+			cfg:           cfg,
+			wantNames:     []string{"prod", "dev"},
+			wantOutputDir: []string{"clusters/prod", "manifests/dev"},
+		},
+		{
+			name:          "should target named environments",
+			cfg:           cfg,
+			envNames:      []string{"dev"},
+			wantNames:     []string{"dev"},
+			wantOutputDir: []string{"manifests/dev"},
+		},
+		{
+			name:          "should target the config without environments",
+			cfg:           &Config{Renderfile: Renderfile{Apps: []App{{Name: "web"}}}},
+			wantNames:     []string{""},
+			wantOutputDir: []string{"manifests"},
+		},
+		{
+			name:         "should fail on unknown environments",
+			cfg:          cfg,
+			envNames:     []string{"staging"},
+			wantErrorMsg: "environment 'staging' not found in config (valid: prod, dev)",
+		},
+		{
+			name: "should fail on overlapping output directories of environments",
+			cfg: &Config{Renderfile: Renderfile{Environments: []Environment{
+				{Name: "prod", OutputDir: "manifests"},
+				{Name: "dev"},
+			}}},
+			envNames:     []string{"prod"},
+			wantErrorMsg: "output directory 'manifests' of environment 'prod' overlaps output directory 'manifests/dev' of environment 'dev'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.EnvironmentTargets(tt.envNames, "manifests")
+			if tt.wantErrorMsg != "" {
+				if err == nil || err.Error() != tt.wantErrorMsg {
+					t.Fatalf("EnvironmentTargets() error = %v, want %q", err, tt.wantErrorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EnvironmentTargets() error = %v", err)
+			}
+			names, outputDirs := make([]string, len(got)), make([]string, len(got))
+			for i, target := range got {
+				names[i], outputDirs[i] = target.Name, target.OutputDir
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) || !reflect.DeepEqual(outputDirs, tt.wantOutputDir) {
+				t.Errorf("EnvironmentTargets() got = %v in %v, want %v in %v", names, outputDirs, tt.wantNames, tt.wantOutputDir)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// getExecDir returns the working directory of an Exec source resolved relative to the config directory,
+// which is also its default.
+func getExecDir(src Exec) string {
+	if src.Dir == "" {
+		return configDir
+	}
+	return resolvePath(src.Dir)
+}
+
+// getExecEnv returns the environment variables of an Exec source in 'key=value' form, sorted by key.
+func getExecEnv(src Exec) []string {
+	env := make([]string, 0, len(src.Env))
+	for _, name := range StringKeys(src.Env) {
+		env = append(env, name+"="+src.Env[name])
+	}
+	return env
+}
+
+// getExecCmdline returns a shell command line equivalent to the execution of an Exec source.
+func getExecCmdline(src Exec) string {
+	parts := make([]string, 0, len(src.Env)+len(src.Command))
+	for _, env := range getExecEnv(src) {
+		parts = append(parts, shellQuote(env))
+	}
+	for _, arg := range src.Command {
+		parts = append(parts, shellQuote(arg))
+	}
+	cmdline := strings.Join(parts, " ")
+	if dir := getExecDir(src); dir != "" {
+		cmdline = fmt.Sprintf("cd %s && %s", shellQuote(dir), cmdline)
+	}
+	return cmdline
+}
+
+// renderExec renders an App Exec object by executing its command and capturing its stdout as manifests.
+func renderExec(appName string, src Exec, opts RenderOptions) (*Render, error) {
+	render := &Render{
+		AppName: appName,
+		SrcName: src.Name,
+		SrcType: "exec",
+	}
+	if len(src.Command) == 0 {
+		render.Err = fmt.Errorf("missing command")
+		return render, render.Err
+	}
+	render.CmdLine = getExecCmdline(src)
+	if opts.DryRun {
+		return render, nil
+	}
+	cmd, stdout, stderr, exitCode, err := execArgs(src.Command, getExecDir(src), getExecEnv(src))
+	if exitCode != 0 {
+		err = fmt.Errorf("%s failed with exit code %d: %s", src.Command[0], exitCode, string(stderr))
+	}
+	render.Cmd = cmd
+	render.Stdout = stdout
+	render.Stderr = stderr
+	render.Err = err
+	return render, err
+}
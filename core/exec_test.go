@@ -0,0 +1,67 @@
+package core
+
+import (
+	"testing"
+)
+
+func Test_renderExec(t *testing.T) {
+	configDir = "../testdata"
+	t.Cleanup(func() { configDir = "" })
+	tests := []struct {
+		name        string
+		src         Exec
+		opts        RenderOptions
+		wantCmdLine string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name: "should capture stdout of command in working directory with env",
+			src: Exec{
+				Name:    "hello",
+				Command: []string{"sh", "-c", `echo "kind: $KIND"; echo "dir: $(basename "$PWD")"`},
+				Dir:     "config",
+				Env:     map[string]string{"KIND": "ConfigMap"},
+			},
+			wantCmdLine: `cd ../testdata/config && KIND=ConfigMap sh -c 'echo "kind: $KIND"; echo "dir: $(basename "$PWD")"'`,
+			want:        "kind: ConfigMap\ndir: config",
+		},
+		{
+			name:        "should not execute command on dry run",
+			src:         Exec{Name: "hello", Command: []string{"false"}},
+			opts:        RenderOptions{DryRun: true},
+			wantCmdLine: "cd ../testdata && false",
+		},
+		{
+			name:        "should fail on exit code",
+			src:         Exec{Name: "hello", Command: []string{"false"}},
+			wantCmdLine: "cd ../testdata && false",
+			wantErr:     true,
+		},
+		{
+			name:        "should fail on missing command",
+			src:         Exec{Name: "hello", Command: []string{"manifestus-missing-command"}},
+			wantCmdLine: "cd ../testdata && manifestus-missing-command",
+			wantErr:     true,
+		},
+		{
+			name:    "should fail without command",
+			src:     Exec{Name: "hello"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderExec("app", tt.src, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderExec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.CmdLine != tt.wantCmdLine {
+				t.Errorf("renderExec() got cmdline = %v, want %v", got.CmdLine, tt.wantCmdLine)
+			}
+			if got.Doc() != tt.want {
+				t.Errorf("renderExec() got = %v, want %v", got.Doc(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceWithFilters is a Source with rules including and excluding the objects rendered from it.
+type SourceWithFilters interface {
+	Source
+
+	// SrcFilters returns the rules including and excluding objects rendered from the source.
+	SrcFilters() Filters
+}
+
+// getSourceFilters returns the rules including and excluding the objects rendered from a source.
+func getSourceFilters(src Source) Filters {
+	if s, ok := src.(SourceWithFilters); ok {
+		return s.SrcFilters()
+	}
+	return Filters{}
+}
+
+// isEmpty returns true if the filters do not remove any objects.
+func (f Filters) isEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// keeps returns true if an object is kept by the filters.
+func (f Filters) keeps(object Object) bool {
+	if len(f.Include) > 0 && !matchesAnyFilter(f.Include, object) {
+		return false
+	}
+	return !matchesAnyFilter(f.Exclude, object)
+}
+
+// matchesAnyFilter returns true if an object matches any of a list of rules.
+func matchesAnyFilter(filters []ObjectFilter, object Object) bool {
+	for _, filter := range filters {
+		if filter.matches(object) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if an object matches all fields set in the rule.
+func (f ObjectFilter) matches(object Object) bool {
+	if !matchGlob(f.APIVersion, object.APIVersion) || !matchGlob(f.Kind, object.Kind) ||
+		!matchGlob(f.Name, object.Name) || !matchGlob(f.Namespace, object.Namespace) {
+		return false
+	}
+	_, metadata := mappingValue(object.Node, "metadata")
+	return matchMetadataValues(metadata, "labels", f.Labels) && matchMetadataValues(metadata, "annotations", f.Annotations)
+}
+
+// matchMetadataValues returns true if a mapping field of the metadata of an object has all keys of a
+// map of globs, with values matching them.
+func matchMetadataValues(metadata *yaml.Node, key string, globs map[string]string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	if metadata == nil {
+		return false
+	}
+	_, values := mappingValue(metadata, key)
+	if values == nil {
+		return false
+	}
+	for k, glob := range globs {
+		_, value := mappingValue(values, k)
+		if value == nil || value.Kind != yaml.ScalarNode || !matchGlob(glob, value.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchGlob returns true if a value matches a glob, whose '*' characters match any characters,
+// including '/'. Empty globs match any value.
+func matchGlob(glob, value string) bool {
+	if glob == "" || glob == "*" {
+		return true
+	}
+	expr := strings.ReplaceAll(regexp.QuoteMeta(glob), `\*`, ".*")
+	return regexp.MustCompile("^" + expr + "$").MatchString(value)
+}
+
+// filterRenders returns copies of renders with the objects removed by filters moved from their stdout
+// to their filtered documents.
+func filterRenders(renders Renders, f Filters) (Renders, error) {
+	filtered := make(Renders, len(renders))
+	for i, render := range renders {
+		copied := *render
+		if len(bytes.TrimSpace(render.Stdout)) > 0 {
+			kept, removed, err := filterManifests(render.Stdout, f)
+			if err != nil {
+				return nil, err
+			}
+			copied.Stdout, copied.Filtered = kept, removed
+		}
+		filtered[i] = &copied
+	}
+	return filtered, nil
+}
+
+// filterManifests returns the documents of manifests with objects kept by filters, and the documents with
+// objects removed by them. Documents are kept as rendered, except Lists and documents with objects both
+// kept and removed, whose objects are re-encoded as separate documents. Manifests without objects removed
+// are returned unchanged.
+func filterManifests(data []byte, f Filters) ([]byte, []byte, error) {
+	kept := make([]string, 0)
+	removed := make([]string, 0)
+	for _, doc := range splitDocuments(string(data)) {
+		objects, err := ParseObjects([]byte(doc))
+		if err != nil {
+			return nil, nil, err
+		}
+		keptObjects := make([]Object, 0, len(objects))
+		removedObjects := make([]Object, 0)
+		for _, object := range objects {
+			if f.keeps(object) {
+				keptObjects = append(keptObjects, object)
+			} else {
+				removedObjects = append(removedObjects, object)
+			}
+		}
+		switch {
+		case len(removedObjects) == 0:
+			kept = append(kept, doc)
+		case len(keptObjects) == 0:
+			removed = append(removed, doc)
+		default:
+			if kept, err = appendEncodedObjects(kept, keptObjects); err != nil {
+				return nil, nil, err
+			}
+			if removed, err = appendEncodedObjects(removed, removedObjects); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if len(removed) == 0 {
+		return data, nil, nil
+	}
+	return joinDocuments(kept), joinDocuments(removed), nil
+}
+
+// appendEncodedObjects appends the objects encoded as separate documents to a list of documents.
+func appendEncodedObjects(docs []string, objects []Object) ([]string, error) {
+	for _, object := range objects {
+		data, err := encodeNode(object.Node)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, string(data))
+	}
+	return docs, nil
+}
+
+// joinDocuments joins YAML documents into a manifest, separating them with document markers.
+func joinDocuments(docs []string) []byte {
+	var data strings.Builder
+	for i, doc := range docs {
+		if i > 0 {
+			data.WriteString("---\n")
+		}
+		data.WriteString(doc)
+		if doc != "" && !strings.HasSuffix(doc, "\n") {
+			data.WriteString("\n")
+		}
+	}
+	return []byte(data.String())
+}
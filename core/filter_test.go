@@ -0,0 +1,158 @@
+package core
+
+import (
+	"testing"
+)
+
+func Test_filterManifests(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		filters     Filters
+		wantKept    string
+		wantRemoved string
+	}{
+		{
+			name: "should exclude Helm hooks by annotation",
+			data: `---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+# Source: web/templates/test.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-test
+  annotations:
+    helm.sh/hook: test
+`,
+			filters: Filters{Exclude: []ObjectFilter{{Annotations: map[string]string{"helm.sh/hook": "*"}}}},
+			wantKept: `---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`,
+			wantRemoved: `# Source: web/templates/test.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-test
+  annotations:
+    helm.sh/hook: test
+`,
+		},
+		{
+			name: "should include only objects matching include rules",
+			data: `apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web-tls
+  namespace: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-settings
+  namespace: web
+`,
+			filters: Filters{Include: []ObjectFilter{{APIVersion: "cert-manager.io/*", Name: "web-*"}}},
+			wantKept: `apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web-tls
+  namespace: web
+`,
+			wantRemoved: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-settings
+  namespace: web
+`,
+		},
+		{
+			name: "should split Lists with objects kept and removed",
+			data: `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ServiceAccount
+  metadata:
+    name: web
+    namespace: web
+- apiVersion: v1
+  kind: ServiceAccount
+  metadata:
+    name: web
+    namespace: kube-system
+`,
+			filters: Filters{Exclude: []ObjectFilter{{Kind: "ServiceAccount", Namespace: "kube-*"}}},
+			wantKept: `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: web
+  namespace: web
+`,
+			wantRemoved: `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: web
+  namespace: kube-system
+`,
+		},
+		{
+			name: "should return manifests without objects removed unchanged",
+			data: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  labels: {app: web}
+`,
+			filters: Filters{Exclude: []ObjectFilter{{Labels: map[string]string{"app": "api"}}}},
+			wantKept: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  labels: {app: web}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, removed, err := filterManifests([]byte(tt.data), tt.filters)
+			if err != nil {
+				t.Fatalf("filterManifests() error = %v", err)
+			}
+			if string(kept) != tt.wantKept {
+				t.Errorf("filterManifests() kept = %s, want %s", kept, tt.wantKept)
+			}
+			if string(removed) != tt.wantRemoved {
+				t.Errorf("filterManifests() removed = %s, want %s", removed, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func Test_matchGlob(t *testing.T) {
+	tests := []struct {
+		glob  string
+		value string
+		want  bool
+	}{
+		{glob: "", value: "anything", want: true},
+		{glob: "*", value: "apps/v1", want: true},
+		{glob: "cert-manager.io/*", value: "cert-manager.io/v1", want: true},
+		{glob: "cert-manager.io/*", value: "acme.cert-manager.io/v1", want: false},
+		{glob: "web-*-tls", value: "web-api-tls", want: true},
+		{glob: "web.tls", value: "web-tls", want: false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.glob, tt.value); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.glob, tt.value, got, tt.want)
+		}
+	}
+}
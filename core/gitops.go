@@ -0,0 +1,260 @@
+package core
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GitOps tools deploying the output files of apps.
+const (
+	// GitOpsArgoCD deploys apps with Argo CD Applications.
+	GitOpsArgoCD = "argocd"
+
+	// GitOpsFlux deploys apps with Flux Kustomizations.
+	GitOpsFlux = "flux"
+)
+
+// gitOpsTools are the valid GitOps tools.
+var gitOpsTools = []string{GitOpsArgoCD, GitOpsFlux}
+
+// Defaults of GitOps objects.
+const (
+	defaultGitOpsDir       = "gitops"
+	defaultArgoCDNamespace = "argocd"
+	defaultArgoCDProject   = "default"
+	defaultArgoCDRevision  = "HEAD"
+	defaultArgoCDServer    = "https://kubernetes.default.svc"
+	defaultFluxNamespace   = "flux-system"
+	defaultFluxSourceRef   = "flux-system"
+	defaultFluxInterval    = "10m"
+)
+
+// merge returns the GitOps of an app with the fields it does not set taken from the GitOps of the Renderfile.
+func (g GitOps) merge(parent GitOps) GitOps {
+	merged := GitOps{
+		Tool:           cmp.Or(g.Tool, parent.Tool),
+		Dir:            cmp.Or(g.Dir, parent.Dir),
+		Namespace:      cmp.Or(g.Namespace, parent.Namespace),
+		Path:           cmp.Or(g.Path, parent.Path),
+		RepoURL:        cmp.Or(g.RepoURL, parent.RepoURL),
+		TargetRevision: cmp.Or(g.TargetRevision, parent.TargetRevision),
+		Project:        cmp.Or(g.Project, parent.Project),
+		SourceRef:      cmp.Or(g.SourceRef, parent.SourceRef),
+		Destination: GitOpsDestination{
+			Server:    cmp.Or(g.Destination.Server, parent.Destination.Server),
+			Name:      cmp.Or(g.Destination.Name, parent.Destination.Name),
+			Namespace: cmp.Or(g.Destination.Namespace, parent.Destination.Namespace),
+		},
+		SyncPolicy: GitOpsSyncPolicy{
+			Automated:   cmp.Or(g.SyncPolicy.Automated, parent.SyncPolicy.Automated),
+			SelfHeal:    cmp.Or(g.SyncPolicy.SelfHeal, parent.SyncPolicy.SelfHeal),
+			SyncOptions: g.SyncPolicy.SyncOptions,
+		},
+		Interval: cmp.Or(g.Interval, parent.Interval),
+		Prune:    cmp.Or(g.Prune, parent.Prune),
+	}
+	if merged.SyncPolicy.SyncOptions == nil {
+		merged.SyncPolicy.SyncOptions = parent.SyncPolicy.SyncOptions
+	}
+	return merged
+}
+
+// WriteGitOpsObjects writes an Argo CD Application or Flux Kustomization for each enabled app with a GitOps
+// tool configured, deploying the output files in the directory of the app in the output directory. The
+// objects are written to the GitOps directory of the directory given, which is the output directory unless
+// checking it, and the paths of the files written are returned relative to the directory.
+func WriteGitOpsObjects(cfg *Config, dir, outputDir string, opts OutputOptions) ([]string, error) {
+	files, err := getGitOpsObjectFiles(cfg, outputDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		p := path.Join(dir, file.path)
+		if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+			return paths, err
+		}
+		if err := os.WriteFile(p, file.data, 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, file.path)
+	}
+	return paths, nil
+}
+
+// getGitOpsObjectFiles returns the files of the GitOps objects of the enabled apps with a GitOps tool configured.
+func getGitOpsObjectFiles(cfg *Config, outputDir string, opts OutputOptions) ([]outputFile, error) {
+	files := make([]outputFile, 0)
+	for _, app := range cfg.EnabledApps() {
+		gitOps := app.GitOps.merge(cfg.Renderfile.GitOps)
+		if gitOps.Tool == "" {
+			continue
+		}
+		file, err := getGitOpsObjectFile(cfg, app.Name, gitOps, outputDir, opts)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// getGitOpsObjectFile returns the file of the GitOps object of an app deploying the output files in its directory.
+func getGitOpsObjectFile(cfg *Config, appName string, gitOps GitOps, outputDir string, opts OutputOptions) (outputFile, error) {
+	outputs, err := GetOutputFiles(cfg, []string{appName}, nil, SrcTypes(), opts)
+	if err != nil {
+		return outputFile{}, err
+	}
+	appOutputs, ok := trimPathPrefix(outputs, appName+"/")
+	if !ok {
+		return outputFile{}, fmt.Errorf("gitops of app '%s' requires its output files in a directory of their own", appName)
+	}
+	appPath := path.Join(cmp.Or(gitOps.Path, outputDir), appName)
+	// Without a kustomization index, Argo CD only deploys the output files in subdirectories of the
+	// directory of the app, like those of the resource layout, if it recurses into them.
+	recurse := !opts.KustomizeIndex && slices.ContainsFunc(appOutputs, func(p string) bool {
+		return strings.Contains(p, "/")
+	})
+
+	var object any
+	switch gitOps.Tool {
+	case GitOpsArgoCD:
+		if gitOps.RepoURL == "" {
+			return outputFile{}, fmt.Errorf("missing repoURL in gitops of app '%s'", appName)
+		}
+		object = newArgoCDApplication(appName, appPath, recurse, gitOps)
+	case GitOpsFlux:
+		object = newFluxKustomization(appName, appPath, gitOps)
+	default:
+		return outputFile{}, fmt.Errorf("unsupported gitops tool '%s' of app '%s' (valid: %s)", gitOps.Tool, appName, strings.Join(gitOpsTools, ", "))
+	}
+
+	var data bytes.Buffer
+	if !opts.NoBanner {
+		data.WriteString(fmt.Sprintf("#:manifestus gitops{appName=%s, tool=%s}\n", appName, gitOps.Tool))
+	}
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(object); err != nil {
+		return outputFile{}, err
+	}
+	if err := encoder.Close(); err != nil {
+		return outputFile{}, err
+	}
+	filePath := path.Join(cmp.Or(gitOps.Dir, defaultGitOpsDir), appName+"."+gitOps.Tool+".yaml")
+	return outputFile{path: filePath, data: data.Bytes()}, nil
+}
+
+// gitOpsMetadata is the metadata of GitOps objects.
+type gitOpsMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+// argoCDApplication is an Argo CD Application.
+type argoCDApplication struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   gitOpsMetadata `yaml:"metadata"`
+	Spec       struct {
+		Project string `yaml:"project"`
+		Source  struct {
+			RepoURL        string           `yaml:"repoURL"`
+			TargetRevision string           `yaml:"targetRevision"`
+			Path           string           `yaml:"path"`
+			Directory      *argoCDDirectory `yaml:"directory,omitempty"`
+		} `yaml:"source"`
+		Destination struct {
+			Server    string `yaml:"server,omitempty"`
+			Name      string `yaml:"name,omitempty"`
+			Namespace string `yaml:"namespace,omitempty"`
+		} `yaml:"destination"`
+		SyncPolicy *argoCDSyncPolicy `yaml:"syncPolicy,omitempty"`
+	} `yaml:"spec"`
+}
+
+// argoCDDirectory are the options of the directory of plain manifests of an Argo CD Application.
+type argoCDDirectory struct {
+	Recurse bool `yaml:"recurse"`
+}
+
+// argoCDSyncPolicy is the sync policy of an Argo CD Application.
+type argoCDSyncPolicy struct {
+	Automated   *argoCDAutomatedSync `yaml:"automated,omitempty"`
+	SyncOptions []string             `yaml:"syncOptions,omitempty"`
+}
+
+// argoCDAutomatedSync are the options of automated syncs of an Argo CD Application.
+type argoCDAutomatedSync struct {
+	Prune    bool `yaml:"prune"`
+	SelfHeal bool `yaml:"selfHeal"`
+}
+
+// newArgoCDApplication returns an Argo CD Application of an app deploying the output files at a path,
+// recursing into its subdirectories if requested.
+func newArgoCDApplication(appName, appPath string, recurse bool, gitOps GitOps) argoCDApplication {
+	app := argoCDApplication{APIVersion: "argoproj.io/v1alpha1", Kind: "Application"}
+	app.Metadata = gitOpsMetadata{Name: appName, Namespace: cmp.Or(gitOps.Namespace, defaultArgoCDNamespace)}
+	app.Spec.Project = cmp.Or(gitOps.Project, defaultArgoCDProject)
+	app.Spec.Source.RepoURL = gitOps.RepoURL
+	app.Spec.Source.TargetRevision = cmp.Or(gitOps.TargetRevision, defaultArgoCDRevision)
+	app.Spec.Source.Path = appPath
+	if recurse {
+		app.Spec.Source.Directory = &argoCDDirectory{Recurse: true}
+	}
+	app.Spec.Destination.Name = gitOps.Destination.Name
+	if app.Spec.Destination.Name == "" {
+		app.Spec.Destination.Server = cmp.Or(gitOps.Destination.Server, defaultArgoCDServer)
+	}
+	app.Spec.Destination.Namespace = gitOps.Destination.Namespace
+	policy := &argoCDSyncPolicy{SyncOptions: gitOps.SyncPolicy.SyncOptions}
+	if isTrue(gitOps.SyncPolicy.Automated) {
+		policy.Automated = &argoCDAutomatedSync{Prune: isTrue(gitOps.Prune), SelfHeal: isTrue(gitOps.SyncPolicy.SelfHeal)}
+	}
+	if policy.Automated != nil || len(policy.SyncOptions) > 0 {
+		app.Spec.SyncPolicy = policy
+	}
+	return app
+}
+
+// fluxKustomization is a Flux Kustomization.
+type fluxKustomization struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   gitOpsMetadata `yaml:"metadata"`
+	Spec       struct {
+		Interval  string `yaml:"interval"`
+		Path      string `yaml:"path"`
+		Prune     bool   `yaml:"prune"`
+		SourceRef struct {
+			Kind string `yaml:"kind"`
+			Name string `yaml:"name"`
+		} `yaml:"sourceRef"`
+		TargetNamespace string `yaml:"targetNamespace,omitempty"`
+	} `yaml:"spec"`
+}
+
+// newFluxKustomization returns a Flux Kustomization of an app deploying the output files at a path.
+func newFluxKustomization(appName, appPath string, gitOps GitOps) fluxKustomization {
+	k := fluxKustomization{APIVersion: "kustomize.toolkit.fluxcd.io/v1", Kind: "Kustomization"}
+	k.Metadata = gitOpsMetadata{Name: appName, Namespace: cmp.Or(gitOps.Namespace, defaultFluxNamespace)}
+	k.Spec.Interval = cmp.Or(gitOps.Interval, defaultFluxInterval)
+	k.Spec.Path = "./" + strings.TrimPrefix(appPath, "./")
+	k.Spec.Prune = isTrue(gitOps.Prune)
+	k.Spec.SourceRef.Kind = "GitRepository"
+	k.Spec.SourceRef.Name = cmp.Or(gitOps.SourceRef, defaultFluxSourceRef)
+	k.Spec.TargetNamespace = gitOps.Destination.Namespace
+	return k
+}
+
+// isTrue returns true if an optional boolean is set and true.
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"testing"
+)
+
+func Test_getGitOpsObjectFile(t *testing.T) {
+	prune := true
+	tests := []struct {
+		name     string
+		app      App
+		gitOps   GitOps
+		opts     OutputOptions
+		wantPath string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "should write an Argo CD Application with app settings overriding renderfile settings",
+			app: App{Name: "web", GitOps: GitOps{
+				Destination: GitOpsDestination{Namespace: "web"},
+				SyncPolicy:  GitOpsSyncPolicy{SyncOptions: []string{"CreateNamespace=true"}},
+			}},
+			gitOps: GitOps{
+				Tool:        GitOpsArgoCD,
+				RepoURL:     "https://example.com/cluster.git",
+				Path:        "clusters/prod",
+				Destination: GitOpsDestination{Namespace: "default"},
+				SyncPolicy:  GitOpsSyncPolicy{Automated: &prune},
+				Prune:       &prune,
+			},
+			opts:     OutputOptions{NoBanner: true},
+			wantPath: "gitops/web.argocd.yaml",
+			want: `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: web
+  namespace: argocd
+spec:
+  project: default
+  source:
+    repoURL: https://example.com/cluster.git
+    targetRevision: HEAD
+    path: clusters/prod/web
+  destination:
+    server: https://kubernetes.default.svc
+    namespace: web
+  syncPolicy:
+    automated:
+      prune: true
+      selfHeal: false
+    syncOptions:
+      - CreateNamespace=true
+`,
+		},
+		{
+			name:     "should write an Argo CD Application recursing into the directories of the resource layout",
+			app:      App{Name: "web", GitOps: GitOps{Tool: GitOpsArgoCD, RepoURL: "https://example.com/cluster.git"}},
+			opts:     OutputOptions{NoBanner: true, Layout: LayoutResource},
+			wantPath: "gitops/web.argocd.yaml",
+			want: `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: web
+  namespace: argocd
+spec:
+  project: default
+  source:
+    repoURL: https://example.com/cluster.git
+    targetRevision: HEAD
+    path: manifests/web
+    directory:
+      recurse: true
+  destination:
+    server: https://kubernetes.default.svc
+`,
+		},
+		{
+			name:     "should write an Argo CD Application deploying the kustomization index of the resource layout",
+			app:      App{Name: "web", GitOps: GitOps{Tool: GitOpsArgoCD, RepoURL: "https://example.com/cluster.git"}},
+			opts:     OutputOptions{NoBanner: true, Layout: LayoutResource, KustomizeIndex: true},
+			wantPath: "gitops/web.argocd.yaml",
+			want: `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: web
+  namespace: argocd
+spec:
+  project: default
+  source:
+    repoURL: https://example.com/cluster.git
+    targetRevision: HEAD
+    path: manifests/web
+  destination:
+    server: https://kubernetes.default.svc
+`,
+		},
+		{
+			name:     "should write a Flux Kustomization",
+			app:      App{Name: "web", GitOps: GitOps{Tool: GitOpsFlux, Dir: "clusters", Interval: "1m"}},
+			opts:     OutputOptions{},
+			wantPath: "clusters/web.flux.yaml",
+			want: `#:manifestus gitops{appName=web, tool=flux}
+apiVersion: kustomize.toolkit.fluxcd.io/v1
+kind: Kustomization
+metadata:
+  name: web
+  namespace: flux-system
+spec:
+  interval: 1m
+  path: ./manifests/web
+  prune: false
+  sourceRef:
+    kind: GitRepository
+    name: flux-system
+`,
+		},
+		{
+			name:    "should reject Argo CD Applications without a repo URL",
+			app:     App{Name: "web", GitOps: GitOps{Tool: GitOpsArgoCD}},
+			wantErr: true,
+		},
+		{
+			name:    "should reject apps without a directory of their own",
+			app:     App{Name: "web", GitOps: GitOps{Tool: GitOpsFlux}},
+			opts:    OutputOptions{PathTemplates: map[string]string{"web": FlattenPathTemplate}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.app.Bundles = []Bundle{{Name: "site"}}
+			cfg := &Config{Renderfile: Renderfile{GitOps: tt.gitOps, Apps: []App{tt.app}}}
+			got, err := getGitOpsObjectFile(cfg, tt.app.Name, tt.app.GitOps.merge(tt.gitOps), "manifests", tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getGitOpsObjectFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.path != tt.wantPath || string(got.data) != tt.want {
+				t.Errorf("getGitOpsObjectFile() got = %s: %s, want %s: %s", got.path, got.data, tt.wantPath, tt.want)
+			}
+		})
+	}
+}
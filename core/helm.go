@@ -0,0 +1,78 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	helmcli "helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+// helmModulePath is the path of the Helm SDK module used to render charts in-process.
+const helmModulePath = "helm.sh/helm/v3"
+
+// defaultHelmNamespace is the namespace 'helm template' renders charts into if none is given.
+const defaultHelmNamespace = "default"
+
+// renderHelmChart renders a local Helm chart directory or packaged chart in-process with the
+// Helm SDK. Its output is the same as that of 'helm template' for the same chart and options.
+func renderHelmChart(releaseName, chartPath, namespace, kubeVersion string, valuesFiles []string) ([]byte, error) {
+	if _, err := os.Stat(chartPath); err != nil {
+		return nil, fmt.Errorf("the builtin engine only renders local chart directories and packaged charts: %w", err)
+	}
+	chrt, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, err
+	}
+	if chrt.Metadata.Type != "" && chrt.Metadata.Type != "application" {
+		return nil, fmt.Errorf("%s charts are not installable", chrt.Metadata.Type)
+	}
+	if req := chrt.Metadata.Dependencies; req != nil {
+		if err := action.CheckDependencies(chrt, req); err != nil {
+			return nil, fmt.Errorf("%w: you may need to run 'helm dependency build' to fetch missing dependencies", err)
+		}
+	}
+
+	vals, err := (&values.Options{ValueFiles: valuesFiles}).MergeValues(getter.All(helmcli.New()))
+	if err != nil {
+		return nil, err
+	}
+
+	// Configure the install action the same way 'helm template' does.
+	client := action.NewInstall(&action.Configuration{Log: func(string, ...interface{}) {}})
+	client.DryRun = true
+	client.DryRunOption = "true"
+	client.ReleaseName = releaseName
+	client.Replace = true
+	client.ClientOnly = true
+	client.Namespace = namespace
+	if client.Namespace == "" {
+		client.Namespace = defaultHelmNamespace
+	}
+	if kubeVersion != "" {
+		parsedKubeVersion, err := chartutil.ParseKubeVersion(kubeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kube version '%s': %w", kubeVersion, err)
+		}
+		client.KubeVersion = parsedKubeVersion
+	}
+	rel, err := client.RunWithContext(context.Background(), chrt, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	// Assemble the manifests and hooks of the release the same way 'helm template' does.
+	var manifests bytes.Buffer
+	_, _ = fmt.Fprintln(&manifests, strings.TrimSpace(rel.Manifest))
+	for _, hook := range rel.Hooks {
+		_, _ = fmt.Fprintf(&manifests, "---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
+	}
+	return manifests.Bytes(), nil
+}
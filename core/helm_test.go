@@ -0,0 +1,121 @@
+package core
+
+import (
+	"testing"
+)
+
+func Test_renderHelmChart(t *testing.T) {
+	tests := []struct {
+		name        string
+		namespace   string
+		kubeVersion string
+		valuesFiles []string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name: "defaults",
+			want: `---
+# Source: hello/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: hello
+  namespace: default
+data:
+  message: "Hello, World!"
+  kubeVersion: "v1.20.0"
+---
+# Source: hello/templates/test.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-test
+  annotations:
+    helm.sh/hook: test
+spec:
+  containers:
+  - name: test
+    image: busybox
+`,
+		},
+		{
+			name:        "options",
+			namespace:   "greetings",
+			kubeVersion: "1.31.0",
+			valuesFiles: []string{"../testdata/charts/hello-values.yaml"},
+			want: `---
+# Source: hello/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: hello
+  namespace: greetings
+data:
+  message: "Hello, Values!"
+  kubeVersion: "v1.31.0"
+---
+# Source: hello/templates/test.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: hello-test
+  annotations:
+    helm.sh/hook: test
+spec:
+  containers:
+  - name: test
+    image: busybox
+`,
+		},
+		{
+			name:        "invalid kube version",
+			kubeVersion: "latest",
+			wantErr:     true,
+		},
+		{
+			name:        "missing values file",
+			valuesFiles: []string{"../testdata/charts/missing.yaml"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderHelmChart("hello", "../testdata/charts/hello", tt.namespace, tt.kubeVersion, tt.valuesFiles)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderHelmChart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("renderHelmChart() got = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func Test_renderHelmChart_remote(t *testing.T) {
+	if _, err := renderHelmChart("hello", "bitnami/nginx", "", "", nil); err == nil {
+		t.Errorf("renderHelmChart() expected error rendering a chart from a repository")
+	}
+}
+
+func Test_execHelmTemplateCmdline(t *testing.T) {
+	release := Release{
+		Name:      "hello",
+		Chart:     "jetstack/cert-manager",
+		Version:   "v1.16.0",
+		Namespace: "cert-manager",
+		Values:    StringList{"/config/my values.yaml"},
+	}
+	cmdline, _, _, _, err := execHelmTemplateCmdline(release, false, true)
+	if err != nil {
+		t.Fatalf("execHelmTemplateCmdline() error = %v", err)
+	}
+	want := "helm template hello jetstack/cert-manager --version v1.16.0 --namespace cert-manager --values '/config/my values.yaml'"
+	if cmdline != want {
+		t.Errorf("execHelmTemplateCmdline() got = %v, want %v", cmdline, want)
+	}
+	args := getHelmTemplateArgs(release, false)
+	if got := args[len(args)-1]; got != "/config/my values.yaml" {
+		t.Errorf("getHelmTemplateArgs() got values arg = %q, want %q", got, "/config/my values.yaml")
+	}
+}
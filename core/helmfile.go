@@ -3,6 +3,8 @@ package core
 import (
 	"fmt"
 	"os"
+	"path"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -41,3 +43,110 @@ func loadHelmfile(path string) (*Helmfile, error) {
 	}
 	return helmfile, nil
 }
+
+// getHelmfileReferencedFiles returns the local files referenced by a Helmfile, such as values
+// files and environment values files. As a Helmfile is a template and not necessarily valid
+// YAML, every list item and mapping value that is the path of an existing file relative to the
+// Helmfile directory is considered a reference.
+func getHelmfileReferencedFiles(helmfile string) ([]string, error) {
+	data, err := os.ReadFile(helmfile)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		var value string
+		if strings.HasPrefix(line, "- ") {
+			value = strings.TrimPrefix(line, "- ")
+		} else if _, after, ok := strings.Cut(line, ": "); ok {
+			value = after
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if value == "" || strings.Contains(value, "{{") {
+			continue
+		}
+		file := path.Join(path.Dir(helmfile), value)
+		if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() || contains(files, file) {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// hasHelmfileUnpinnedCharts returns true if a Helmfile, or a Helmfile it includes with its 'helmfiles' or
+// 'bases' fields, has a release of a remote chart without a pinned version, or includes a Helmfile that is
+// not a local file. Like getHelmfileReferencedFiles, the Helmfile is read line by line as it is a template.
+// Templated versions are pinned, as they are expanded from the Helmfile and the files it references.
+func hasHelmfileUnpinnedCharts(helmfile string) (bool, error) {
+	data, err := os.ReadFile(helmfile)
+	if err != nil {
+		return false, err
+	}
+	section, itemIndent := "", -1
+	var chart, version string
+	unpinned := func() bool {
+		return chart != "" && !isPinnedVersion(version) && !isHelmfileLocalChart(helmfile, chart)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "{{") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == 0 && !strings.HasPrefix(trimmed, "- ") {
+			if unpinned() {
+				return true, nil
+			}
+			section, _, _ = strings.Cut(trimmed, ":")
+			itemIndent, chart, version = -1, "", ""
+			continue
+		}
+		content := trimmed
+		if strings.HasPrefix(trimmed, "- ") && (itemIndent < 0 || indent == itemIndent) {
+			if unpinned() {
+				return true, nil
+			}
+			itemIndent, chart, version = indent, "", ""
+			content = strings.TrimPrefix(trimmed, "- ")
+		} else if indent != itemIndent+2 {
+			continue
+		}
+		key, value, ok := strings.Cut(content, ": ")
+		if !ok && strings.HasSuffix(content, ":") {
+			key, ok = strings.TrimSuffix(content, ":"), true
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		var include string
+		switch {
+		case section == "releases" && key == "chart":
+			chart = value
+		case section == "releases" && key == "version":
+			version = value
+		case section == "helmfiles" && key == "path":
+			include = value
+		case (section == "helmfiles" || section == "bases") && !ok:
+			include = strings.Trim(content, `"'`)
+		}
+		if include == "" {
+			continue
+		}
+		file := path.Join(path.Dir(helmfile), include)
+		if strings.Contains(include, "{{") || !pathExists(file) {
+			return true, nil
+		}
+		if found, err := hasHelmfileUnpinnedCharts(file); found || err != nil {
+			return found, err
+		}
+	}
+	return unpinned(), nil
+}
+
+// isHelmfileLocalChart returns true if the chart of a release in a Helmfile is a local chart.
+func isHelmfileLocalChart(helmfile, chart string) bool {
+	if strings.Contains(chart, "{{") {
+		return false
+	}
+	return strings.HasPrefix(chart, "./") || strings.HasPrefix(chart, "../") || path.IsAbs(chart) || pathExists(path.Join(path.Dir(helmfile), chart))
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceWithIgnoredDifferences is a Source with fields of the objects rendered from it whose differences are ignored.
+type SourceWithIgnoredDifferences interface {
+	Source
+
+	// SrcIgnoreDifferences returns the fields of objects rendered from the source whose differences are ignored.
+	SrcIgnoreDifferences() []IgnoreDifference
+}
+
+// PreserveIgnoredDifferences sets the fields of objects of manifests whose differences are ignored by their
+// sources to their values in the existing output files in an output directory, so that writing the manifests
+// keeps the values, and checking the output files does not compare them. Fields of objects not in existing
+// output files keep their rendered values. Documents of objects with ignored fields are re-encoded, whether
+// their values were changed or not, so their formatting does not depend on the existing output files.
+func PreserveIgnoredDifferences(cfg *Config, manifests []*Manifest, outputDir string, opts OutputOptions) error {
+	for _, manifest := range manifests {
+		src, err := findSource(cfg, manifest.AppName, manifest.SrcType, manifest.SrcName)
+		if err != nil {
+			return err
+		}
+		s, ok := src.(SourceWithIgnoredDifferences)
+		if !ok || len(s.SrcIgnoreDifferences()) == 0 {
+			continue
+		}
+		outputPath, err := manifest.OutputPath(opts)
+		if err != nil {
+			return err
+		}
+		existing, err := ReadOutputObjects(outputDir, []string{outputPath})
+		if err != nil {
+			return err
+		}
+		existingByKey := make(map[ObjectKey]*yaml.Node, len(existing))
+		for _, object := range existing {
+			existingByKey[object.ObjectKey] = object.Node
+		}
+		for _, render := range manifest.Renders {
+			stdout, err := preserveFields(render.Stdout, s.SrcIgnoreDifferences(), existingByKey)
+			if err != nil {
+				return fmt.Errorf("failed to preserve ignored differences of %s '%s' of app '%s': %w", manifest.SrcType, manifest.SrcName, manifest.AppName, err)
+			}
+			render.Stdout = stdout
+		}
+	}
+	return nil
+}
+
+// preserveFields returns manifests with the ignored fields of their objects set to their values in existing
+// objects by their keys. Documents with objects with ignored fields are re-encoded, and Lists as separate
+// documents of their objects. Manifests without objects with ignored fields are returned unchanged.
+func preserveFields(data []byte, ignores []IgnoreDifference, existing map[ObjectKey]*yaml.Node) ([]byte, error) {
+	pointers := make([][][]string, len(ignores))
+	for i, ignore := range ignores {
+		for _, pointer := range ignore.JSONPointers {
+			segments, err := parsePointer(pointer)
+			if err != nil {
+				return nil, err
+			}
+			pointers[i] = append(pointers[i], segments)
+		}
+	}
+
+	docs := make([]string, 0)
+	changed := false
+	for _, doc := range splitDocuments(string(data)) {
+		objects, err := ParseObjects([]byte(doc))
+		if err != nil {
+			return nil, err
+		}
+		matched := false
+		for _, object := range objects {
+			for i, ignore := range ignores {
+				if !ignore.ObjectFilter.matches(object) {
+					continue
+				}
+				matched = true
+				if src, ok := existing[object.ObjectKey]; ok {
+					for _, segments := range pointers[i] {
+						copyFields(object.Node, src, segments)
+					}
+				}
+			}
+		}
+		if !matched {
+			docs = append(docs, doc)
+			continue
+		}
+		if docs, err = appendEncodedObjects(docs, objects); err != nil {
+			return nil, err
+		}
+		changed = true
+	}
+	if !changed {
+		return data, nil
+	}
+	return joinDocuments(docs), nil
+}
+
+// copyFields sets the fields at the path of segments in a node to their values in another node, whose '*'
+// segments match any key or index. Fields missing from the node are added, and fields missing from the other
+// node removed, if their parents exist in both nodes.
+func copyFields(dst, src *yaml.Node, segments []string) {
+	if len(segments) == 0 {
+		*dst = *src
+		return
+	}
+	segment, rest := segments[0], segments[1:]
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		if len(rest) == 0 {
+			content := make([]*yaml.Node, 0, len(dst.Content))
+			for i := 0; i+1 < len(dst.Content); i += 2 {
+				key := dst.Content[i].Value
+				if _, value := mappingValue(src, key); value == nil && (segment == "*" || segment == key) {
+					continue
+				}
+				content = append(content, dst.Content[i], dst.Content[i+1])
+			}
+			dst.Content = content
+		}
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key := src.Content[i].Value
+			if segment != "*" && segment != key {
+				continue
+			}
+			if _, value := mappingValue(dst, key); value != nil {
+				copyFields(value, src.Content[i+1], rest)
+			} else if len(rest) == 0 {
+				dst.Content = append(dst.Content, src.Content[i], src.Content[i+1])
+			}
+		}
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		for i := 0; i < len(dst.Content) && i < len(src.Content); i++ {
+			if segment == "*" || segment == strconv.Itoa(i) {
+				copyFields(dst.Content[i], src.Content[i], rest)
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreserveIgnoredDifferences(t *testing.T) {
+	committed := `#:manifestus render{appName=web, srcName=web, srcType=bundle}
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web
+  namespace: web
+data:
+  token: Y29tbWl0dGVk
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: web
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/config: committed
+`
+	stdout := `apiVersion: v1
+kind: Secret
+metadata:
+  name: web
+  namespace: web
+data:
+  token: cmVuZGVyZWQ=
+  extra: cmVuZGVyZWQ=
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: web
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/config: rendered
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: web
+data:
+  token: rendered
+`
+	tests := []struct {
+		name    string
+		ignores []IgnoreDifference
+		want    string
+	}{
+		{
+			name:    "should not change manifests without ignored differences",
+			ignores: nil,
+			want:    stdout,
+		},
+		{
+			name: "should keep committed values of ignored fields of matching objects",
+			ignores: []IgnoreDifference{
+				{JSONPointers: []string{"/data/token", "/data/extra"}, ObjectFilter: ObjectFilter{Kind: "Secret"}},
+				{JSONPointers: []string{"/spec/template/metadata/annotations/checksum~1config"}, ObjectFilter: ObjectFilter{Kind: "Deployment"}},
+			},
+			want: `apiVersion: v1
+kind: Secret
+metadata:
+  name: web
+  namespace: web
+data:
+  token: Y29tbWl0dGVk
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: web
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/config: committed
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: web
+data:
+  token: rendered
+`,
+		},
+		{
+			name: "should keep rendered values of objects not committed",
+			ignores: []IgnoreDifference{
+				{JSONPointers: []string{"/data/*"}, ObjectFilter: ObjectFilter{Kind: "ConfigMap"}},
+			},
+			want: stdout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Renderfile: Renderfile{Apps: []App{{
+				Name:    "web",
+				Bundles: []Bundle{{Name: "web", SourceRules: SourceRules{IgnoredDifferences: IgnoredDifferences{IgnoreDifferences: tt.ignores}}}},
+			}}}}
+			manifests := GetManifests([]*Render{{AppName: "web", SrcName: "web", SrcType: "bundle", Stdout: []byte(stdout)}})
+			outputPath, err := manifests[0].OutputPath(OutputOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			outputDir := t.TempDir()
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(outputDir, outputPath)), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(outputDir, outputPath), []byte(committed), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := PreserveIgnoredDifferences(cfg, manifests, outputDir, OutputOptions{}); err != nil {
+				t.Fatalf("PreserveIgnoredDifferences() error = %v", err)
+			}
+			if got := string(manifests[0].Renders[0].Stdout); got != tt.want {
+				t.Errorf("PreserveIgnoredDifferences() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
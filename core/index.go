@@ -0,0 +1,145 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kustomizationIndexFile is the name of the kustomization files generated to index output files.
+const kustomizationIndexFile = "kustomization.yaml"
+
+// kustomizationIndex is a kustomization listing output files as its resources.
+type kustomizationIndex struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Resources  []string `yaml:"resources"`
+}
+
+// WriteKustomizationIndexes writes a kustomization.yaml listing the output files of each enabled app
+// in its directory, and one at the root of the output directory listing the app directories. Output
+// files of apps not in a directory of their own, such as flattened output files, are listed in the
+// kustomization.yaml of the output directory instead. It returns the paths of the files written
+// relative to the output directory.
+func WriteKustomizationIndexes(cfg *Config, outputDir string, opts OutputOptions) ([]string, error) {
+	files, err := getKustomizationIndexes(cfg, outputDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		p := path.Join(outputDir, file.path)
+		if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+			return paths, err
+		}
+		if err := os.WriteFile(p, file.data, 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, file.path)
+	}
+	return paths, nil
+}
+
+// getKustomizationIndexes returns the kustomization files indexing the output files of the enabled apps
+// in an output directory. Only output files existing in the output directory are listed.
+func getKustomizationIndexes(cfg *Config, outputDir string, opts OutputOptions) ([]outputFile, error) {
+	files := make([]outputFile, 0)
+	rootResources := make([]string, 0)
+	for _, appName := range cfg.EnabledAppNames() {
+		resources, err := getAppResources(cfg, appName, outputDir, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(resources) == 0 {
+			continue
+		}
+		appResources, ok := trimPathPrefix(resources, appName+"/")
+		if !ok {
+			rootResources = append(rootResources, resources...)
+			continue
+		}
+		data, err := getKustomizationIndexData(appResources, opts.NoBanner)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, outputFile{path: path.Join(appName, kustomizationIndexFile), data: data})
+		rootResources = append(rootResources, appName)
+	}
+	sort.Strings(rootResources)
+	data, err := getKustomizationIndexData(rootResources, opts.NoBanner)
+	if err != nil {
+		return nil, err
+	}
+	return append(files, outputFile{path: kustomizationIndexFile, data: data}), nil
+}
+
+// getAppResources returns the sorted paths of the existing output files of an app relative to an output directory.
+func getAppResources(cfg *Config, appName, outputDir string, opts OutputOptions) ([]string, error) {
+	outputs, err := GetOutputFiles(cfg, []string{appName}, nil, SrcTypes(), opts)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]string, 0)
+	for _, output := range outputs {
+		files, err := expandOutputPath(outputDir, output)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if !pathExists(file) {
+				continue
+			}
+			rel, err := filepath.Rel(outputDir, file)
+			if err != nil {
+				return nil, err
+			}
+			resource := filepath.ToSlash(rel)
+			if path.Base(resource) == kustomizationIndexFile {
+				return nil, fmt.Errorf("output file %s of app '%s' conflicts with generated kustomization indexes", resource, appName)
+			}
+			resources = append(resources, resource)
+		}
+	}
+	sort.Strings(resources)
+	return resources, nil
+}
+
+// trimPathPrefix returns the paths with a prefix trimmed, or false if not all paths have the prefix.
+func trimPathPrefix(paths []string, prefix string) ([]string, bool) {
+	trimmed := make([]string, len(paths))
+	for i, p := range paths {
+		if !strings.HasPrefix(p, prefix) {
+			return nil, false
+		}
+		trimmed[i] = strings.TrimPrefix(p, prefix)
+	}
+	return trimmed, true
+}
+
+// getKustomizationIndexData returns the contents of a kustomization file listing resources.
+func getKustomizationIndexData(resources []string, noBanner bool) ([]byte, error) {
+	var data bytes.Buffer
+	if !noBanner {
+		data.WriteString("#:manifestus kustomization index\n")
+	}
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(2)
+	index := kustomizationIndex{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  resources,
+	}
+	if err := encoder.Encode(index); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return data.Bytes(), nil
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteKustomizationIndexes(t *testing.T) {
+	cfg := &Config{Renderfile: Renderfile{Apps: []App{
+		{Name: "web", Bundles: []Bundle{{Name: "site"}}},
+		{Name: "flat", Output: Output{PathTemplate: "{app}-{source}.yaml"}, Bundles: []Bundle{{Name: "site"}}},
+		{Name: "empty", Bundles: []Bundle{{Name: "site"}}},
+		{Name: "off", Disabled: true, Bundles: []Bundle{{Name: "site"}}},
+	}}}
+	opts := OutputOptions{NoBanner: true, Layout: LayoutResource, PathTemplates: cfg.OutputPathTemplates()}
+
+	// Write the manifests of all enabled apps but the empty one.
+	outputDir := t.TempDir()
+	manifests := []*Manifest{
+		{AppName: "web", SrcName: "site", SrcType: "bundle", Renders: Renders{{Stdout: []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: web\n")}}},
+		{AppName: "flat", SrcName: "site", SrcType: "bundle", Renders: Renders{{Stdout: []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: flat\n")}}},
+	}
+	for _, m := range manifests {
+		if _, err := m.Write(outputDir, opts); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	paths, err := WriteKustomizationIndexes(cfg, outputDir, opts)
+	if err != nil {
+		t.Fatalf("WriteKustomizationIndexes() error = %v", err)
+	}
+	if want := []string{"web/kustomization.yaml", "kustomization.yaml"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("WriteKustomizationIndexes() got = %v, want %v", paths, want)
+	}
+	for p, want := range map[string]string{
+		"kustomization.yaml":     "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n  - flat-site.yaml\n  - web\n",
+		"web/kustomization.yaml": "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n  - site.bundle/namespace-web.yaml\n",
+	} {
+		data, err := os.ReadFile(path.Join(outputDir, p))
+		if err != nil || string(data) != want {
+			t.Errorf("WriteKustomizationIndexes() %s got = %q, error = %v, want %q", p, data, err, want)
+		}
+	}
+
+	// The output directory can be built with kustomize.
+	data, err := buildKustomization(outputDir, Kustomization{})
+	if err != nil {
+		t.Fatalf("buildKustomization() error = %v", err)
+	}
+	for _, name := range []string{"name: flat", "name: web"} {
+		if !strings.Contains(string(data), name) {
+			t.Errorf("buildKustomization() got = %s, want to contain %s", data, name)
+		}
+	}
+}
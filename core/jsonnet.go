@@ -0,0 +1,70 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/google/go-jsonnet"
+)
+
+// getJsonnetCmdline returns a 'jsonnet' command line equivalent to the evaluation of a Jsonnet source.
+func getJsonnetCmdline(src Jsonnet) string {
+	args := []string{"jsonnet"}
+	for _, dir := range getJsonnetJPath(src) {
+		args = append(args, "--jpath", dir)
+	}
+	for _, name := range StringKeys(src.ExtVars) {
+		args = append(args, "--ext-str", name+"="+src.ExtVars[name])
+	}
+	for _, name := range StringKeys(src.TLAs) {
+		args = append(args, "--tla-str", name+"="+src.TLAs[name])
+	}
+	return formatCmdline(append(args, resolvePath(src.Main)))
+}
+
+// getJsonnetJPath returns the library search directories of a Jsonnet source resolved relative to the config directory.
+func getJsonnetJPath(src Jsonnet) []string {
+	dirs := make([]string, len(src.JPath))
+	for i, dir := range src.JPath {
+		dirs[i] = resolvePath(dir)
+	}
+	return dirs
+}
+
+// evaluateJsonnet evaluates the main file of a Jsonnet source in-process with go-jsonnet,
+// and returns the Kubernetes objects it evaluates to as YAML manifests.
+func evaluateJsonnet(src Jsonnet) ([]byte, error) {
+	if src.Main == "" {
+		return nil, fmt.Errorf("missing main file")
+	}
+	vm := jsonnet.MakeVM()
+	vm.Importer(&jsonnet.FileImporter{JPaths: getJsonnetJPath(src)})
+	for name, value := range src.ExtVars {
+		vm.ExtVar(name, value)
+	}
+	for name, value := range src.TLAs {
+		vm.TLAVar(name, value)
+	}
+	output, err := vm.EvaluateFile(resolvePath(src.Main))
+	if err != nil {
+		return nil, err
+	}
+	return jsonToManifests([]byte(output))
+}
+
+// renderJsonnet renders an App Jsonnet object.
+func renderJsonnet(appName string, src Jsonnet, opts RenderOptions) (*Render, error) {
+	render := &Render{
+		AppName: appName,
+		SrcName: src.Name,
+		SrcType: "jsonnet",
+		CmdLine: getJsonnetCmdline(src), // No command executed for Jsonnet evaluated in-process. Diagnostic only.
+	}
+	if opts.DryRun {
+		return render, nil
+	}
+	render.Stdout, render.Err = evaluateJsonnet(src)
+	if render.Err != nil {
+		render.Err = fmt.Errorf("jsonnet evaluation failed: %w", render.Err)
+	}
+	return render, render.Err
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"testing"
+)
+
+func Test_evaluateJsonnet(t *testing.T) {
+	configDir = "../testdata/jsonnet"
+	t.Cleanup(func() { configDir = "" })
+	tests := []struct {
+		name    string
+		src     Jsonnet
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "should evaluate with jpath, ext vars and TLAs",
+			src: Jsonnet{
+				Name:    "hello",
+				Main:    "main.jsonnet",
+				JPath:   []string{"vendor", "lib"},
+				ExtVars: map[string]string{"message": "Hello, World!"},
+				TLAs:    map[string]string{"replicas": "3"},
+			},
+			want: `apiVersion: v1
+data:
+  message: Hello, World!
+  port: "8080"
+kind: ConfigMap
+metadata:
+  name: hello
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hello
+spec:
+  replicas: 3
+`,
+		},
+		{
+			name:    "should fail on missing ext vars",
+			src:     Jsonnet{Name: "hello", Main: "main.jsonnet", JPath: []string{"lib"}},
+			wantErr: true,
+		},
+		{
+			name:    "should fail on missing main file",
+			src:     Jsonnet{Name: "hello"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluateJsonnet(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("evaluateJsonnet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("evaluateJsonnet() got = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func Test_getJsonnetCmdline(t *testing.T) {
+	configDir = "config"
+	t.Cleanup(func() { configDir = "" })
+	src := Jsonnet{
+		Main:    "main.jsonnet",
+		JPath:   []string{"lib"},
+		ExtVars: map[string]string{"env": "prod", "cluster": "east", "owner": "Jane's team"},
+		TLAs:    map[string]string{"replicas": "3"},
+	}
+	want := `jsonnet --jpath config/lib --ext-str cluster=east --ext-str env=prod --ext-str 'owner=Jane'\''s team' --tla-str replicas=3 config/main.jsonnet`
+	if got := getJsonnetCmdline(src); got != want {
+		t.Errorf("getJsonnetCmdline() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// kustomizeModulePath is the path of the kustomize API module used to build kustomizations in-process.
+const kustomizeModulePath = "sigs.k8s.io/kustomize/api"
+
+// loadRestrictors is a mapping of the load restrictors of kustomizations to kustomize load restrictions.
+var loadRestrictors = map[string]types.LoadRestrictions{
+	"LoadRestrictionsRootOnly": types.LoadRestrictionsRootOnly,
+	"LoadRestrictionsNone":     types.LoadRestrictionsNone,
+}
+
+// buildKustomization builds a local kustomization directory in-process with the kustomize API.
+// Its output is the same as that of 'kustomize build' for the same directory and options.
+// Components are added to the build by wrapping the directory in a temporary kustomization.
+func buildKustomization(dir string, kustomization Kustomization) ([]byte, error) {
+	opts := krusty.MakeDefaultOptions()
+	if kustomization.LoadRestrictor != "" {
+		restrictions, ok := loadRestrictors[kustomization.LoadRestrictor]
+		if !ok {
+			return nil, fmt.Errorf("invalid load restrictor '%s'", kustomization.LoadRestrictor)
+		}
+		opts.LoadRestrictions = restrictions
+	}
+	// Configure plugins the same way 'kustomize build' does.
+	if kustomization.EnableAlphaPlugins {
+		opts.PluginConfig = types.EnabledPluginConfig(types.BploUseStaticallyLinked)
+	}
+	opts.PluginConfig.HelmConfig.Enabled = kustomization.EnableHelm
+	opts.PluginConfig.HelmConfig.Command = "helm"
+
+	if len(kustomization.Components) > 0 {
+		wrapper, err := makeComponentsKustomization(dir, getKustomizationComponents(kustomization))
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.RemoveAll(wrapper) }()
+		dir = wrapper
+	}
+	resources, err := krusty.MakeKustomizer(opts).Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, err
+	}
+	return resources.AsYaml()
+}
+
+// makeComponentsKustomization makes a temporary kustomization directory with a kustomization
+// directory as its resource and components added to it, and returns its path.
+func makeComponentsKustomization(dir string, components []string) (string, error) {
+	tmp, err := os.MkdirTemp("", "manifestus-kustomization-")
+	if err != nil {
+		return "", err
+	}
+	wrapper := struct {
+		APIVersion string   `yaml:"apiVersion"`
+		Kind       string   `yaml:"kind"`
+		Resources  []string `yaml:"resources"`
+		Components []string `yaml:"components"`
+	}{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+	}
+	// Paths are made relative to the temporary directory, as kustomize does not load absolute ones.
+	for i, p := range append([]string{dir}, components...) {
+		rel, err := relPath(tmp, p)
+		if err != nil {
+			_ = os.RemoveAll(tmp)
+			return "", err
+		}
+		if i == 0 {
+			wrapper.Resources = []string{rel}
+		} else {
+			wrapper.Components = append(wrapper.Components, rel)
+		}
+	}
+	data, err := yaml.Marshal(wrapper)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(tmp, kustomizationFileNames[0]), data, 0644)
+	}
+	if err != nil {
+		_ = os.RemoveAll(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+// relPath returns the path of target relative to the base directory.
+func relPath(base, target string) (string, error) {
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(base, abs)
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"testing"
+)
+
+func Test_buildKustomization(t *testing.T) {
+	tests := []struct {
+		name          string
+		kustomization Kustomization
+		want          string
+		wantErr       bool
+	}{
+		{
+			name:          "defaults",
+			kustomization: Kustomization{Name: "hello"},
+			want: `apiVersion: v1
+data:
+  message: Hello, World!
+kind: ConfigMap
+metadata:
+  name: hello
+  namespace: greetings
+`,
+		},
+		{
+			name: "components",
+			kustomization: Kustomization{
+				Name:       "hello",
+				Components: []string{"../testdata/kustomizations/components/greeting"},
+			},
+			want: `apiVersion: v1
+data:
+  message: HELLO, WORLD!
+kind: ConfigMap
+metadata:
+  labels:
+    greeting: loud
+  name: hello
+  namespace: greetings
+`,
+		},
+		{
+			name:          "invalid load restrictor",
+			kustomization: Kustomization{Name: "hello", LoadRestrictor: "LoadRestrictionsSome"},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildKustomization("../testdata/kustomizations/hello", tt.kustomization)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildKustomization() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("buildKustomization() got = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func Test_execKustomizeBuildCmd(t *testing.T) {
+	kustomization := Kustomization{
+		Source:             "https://github.com/some/repo/config",
+		LoadRestrictor:     "LoadRestrictionsNone",
+		EnableHelm:         true,
+		EnableAlphaPlugins: true,
+	}
+	cmdline, _, _, _, err := execKustomizeBuildCmd(kustomization, true)
+	if err != nil {
+		t.Fatalf("execKustomizeBuildCmd() error = %v", err)
+	}
+	want := "kustomize build https://github.com/some/repo/config --load-restrictor LoadRestrictionsNone --enable-helm --enable-alpha-plugins"
+	if cmdline != want {
+		t.Errorf("execKustomizeBuildCmd() got = %v, want %v", cmdline, want)
+	}
+
+	// Components can only be added to builds by the builtin engine.
+	kustomization.Components = []string{"components/greeting"}
+	if _, _, _, _, err := execKustomizeBuildCmd(kustomization, true); err == nil {
+		t.Errorf("execKustomizeBuildCmd() expected error with components")
+	}
+}
@@ -0,0 +1,297 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severities of lint findings.
+const (
+	// LintError is the severity of findings of objects likely to be insecure or broken.
+	LintError = "error"
+
+	// LintWarning is the severity of findings of objects not following best practices.
+	LintWarning = "warning"
+)
+
+// LintSeverities are the valid severities of lint findings, from most to least severe.
+var LintSeverities = []string{LintError, LintWarning}
+
+// LintRule is a rule checking objects rendered from sources.
+type LintRule struct {
+	// ID identifies the rule in suppressions and findings.
+	ID string
+
+	// Severity is the severity of the findings of the rule, one of LintSeverities.
+	Severity string
+
+	// Description describes what the rule checks.
+	Description string
+
+	// check returns the messages of the findings of the rule in an object.
+	check func(object Object) []string
+}
+
+// lintRules are the built-in lint rules, in order of their findings of an object.
+var lintRules = []LintRule{
+	{ID: "image-tag", Severity: LintWarning, Description: "Container images must have a tag other than 'latest' or a digest", check: checkImageTags},
+	{ID: "resources", Severity: LintWarning, Description: "Containers must have resource requests and limits", check: checkResources},
+	{ID: "privileged", Severity: LintError, Description: "Containers must not be privileged", check: checkPrivileged},
+	{ID: "probes", Severity: LintWarning, Description: "Containers of long-running workloads must have liveness and readiness probes", check: checkProbes},
+	{ID: "host-path", Severity: LintError, Description: "Pods must not mount hostPath volumes", check: checkHostPaths},
+}
+
+// LintRules returns the built-in lint rules.
+func LintRules() []LintRule {
+	return append([]LintRule(nil), lintRules...)
+}
+
+// LintRuleIDs returns the IDs of the built-in lint rules.
+func LintRuleIDs() []string {
+	ids := make([]string, len(lintRules))
+	for i, rule := range lintRules {
+		ids[i] = rule.ID
+	}
+	return ids
+}
+
+// LintFinding is a finding of a lint rule in an object rendered from a source.
+type LintFinding struct {
+	// AppName is the name of the app of the source.
+	AppName string
+
+	// SrcName is the name of the source.
+	SrcName string
+
+	// SrcType is the type of the source.
+	SrcType string
+
+	// Object is the key of the object.
+	Object ObjectKey
+
+	// Rule is the ID of the lint rule.
+	Rule string
+
+	// Severity is the severity of the lint rule.
+	Severity string
+
+	// Message describes the finding.
+	Message string
+}
+
+// String returns the severity, object, source and message of the finding.
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s: %s of %s '%s' of app '%s': %s [%s]", f.Severity, f.Object, f.SrcType, f.SrcName, f.AppName, f.Message, f.Rule)
+}
+
+// SourceWithLintSuppressions is a Source with lint rules suppressed for the objects rendered from it.
+type SourceWithLintSuppressions interface {
+	Source
+
+	// SrcSuppressLint returns the lint rules suppressed for objects rendered from the source.
+	SrcSuppressLint() []LintSuppression
+}
+
+// Lint returns the findings of the built-in lint rules in the objects of manifests, except the rules
+// suppressed for them by the sources of the manifests. Findings are in order of the manifests.
+func Lint(cfg *Config, manifests []*Manifest) ([]LintFinding, error) {
+	findings := make([]LintFinding, 0)
+	for _, manifest := range manifests {
+		objects, err := GetRenderObjects(manifest.Renders)
+		if err != nil {
+			return nil, err
+		}
+		src, err := findSource(cfg, manifest.AppName, manifest.SrcType, manifest.SrcName)
+		if err != nil {
+			return nil, err
+		}
+		var suppressions []LintSuppression
+		if s, ok := src.(SourceWithLintSuppressions); ok {
+			suppressions = s.SrcSuppressLint()
+		}
+		for _, object := range objects {
+			for _, rule := range lintRules {
+				if isLintSuppressed(suppressions, rule.ID, object) {
+					continue
+				}
+				for _, message := range rule.check(object) {
+					findings = append(findings, LintFinding{
+						AppName:  manifest.AppName,
+						SrcName:  manifest.SrcName,
+						SrcType:  manifest.SrcType,
+						Object:   object.ObjectKey,
+						Rule:     rule.ID,
+						Severity: rule.Severity,
+						Message:  message,
+					})
+				}
+			}
+		}
+	}
+	return findings, nil
+}
+
+// isLintSuppressed returns true if a lint rule is suppressed for an object by any suppression.
+func isLintSuppressed(suppressions []LintSuppression, ruleID string, object Object) bool {
+	for _, s := range suppressions {
+		if (len(s.Rules) == 0 || contains(s.Rules, ruleID)) && s.ObjectFilter.matches(object) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLintSeverityAtLeast returns true if a severity is at least as severe as another, which are LintSeverities.
+func IsLintSeverityAtLeast(severity, threshold string) bool {
+	for _, s := range LintSeverities {
+		if s == severity {
+			return true
+		}
+		if s == threshold {
+			return false
+		}
+	}
+	return false
+}
+
+// podSpecPaths are the paths of the pod specs of objects of workload kinds.
+var podSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// longRunningKinds are the workload kinds whose containers are expected to run indefinitely.
+var longRunningKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController"}
+
+// getPodSpec returns the pod spec of an object of a workload kind, or nil if it has none.
+func getPodSpec(object Object) *yaml.Node {
+	keys, ok := podSpecPaths[object.Kind]
+	if !ok {
+		return nil
+	}
+	node := object.Node
+	for _, key := range keys {
+		if _, node = mappingValue(node, key); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// getContainers returns the container nodes in lists of a pod spec, like 'containers' and 'initContainers'.
+func getContainers(podSpec *yaml.Node, keys ...string) []*yaml.Node {
+	containers := make([]*yaml.Node, 0)
+	if podSpec == nil {
+		return containers
+	}
+	for _, key := range keys {
+		_, list := mappingValue(podSpec, key)
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, container := range list.Content {
+			if container.Kind == yaml.MappingNode {
+				containers = append(containers, container)
+			}
+		}
+	}
+	return containers
+}
+
+// checkImageTags checks that the images of containers have a tag other than 'latest', or a digest.
+func checkImageTags(object Object) []string {
+	messages := make([]string, 0)
+	for _, container := range getContainers(getPodSpec(object), "initContainers", "containers") {
+		image := scalarValue(container, "image")
+		if image == "" || strings.Contains(image, "@") {
+			continue
+		}
+		name := image[strings.LastIndex(image, "/")+1:]
+		_, tag, found := strings.Cut(name, ":")
+		if !found {
+			messages = append(messages, fmt.Sprintf("container '%s' uses image '%s' without a tag", scalarValue(container, "name"), image))
+		} else if tag == "latest" {
+			messages = append(messages, fmt.Sprintf("container '%s' uses image '%s' with the 'latest' tag", scalarValue(container, "name"), image))
+		}
+	}
+	return messages
+}
+
+// checkResources checks that containers have resource requests and limits.
+func checkResources(object Object) []string {
+	messages := make([]string, 0)
+	for _, container := range getContainers(getPodSpec(object), "containers") {
+		missing := make([]string, 0)
+		_, resources := mappingValue(container, "resources")
+		for _, key := range []string{"requests", "limits"} {
+			if resources == nil {
+				missing = append(missing, key)
+			} else if _, values := mappingValue(resources, key); values == nil || len(values.Content) == 0 {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			messages = append(messages, fmt.Sprintf("container '%s' has no resource %s", scalarValue(container, "name"), strings.Join(missing, " or ")))
+		}
+	}
+	return messages
+}
+
+// checkPrivileged checks that containers are not privileged.
+func checkPrivileged(object Object) []string {
+	messages := make([]string, 0)
+	for _, container := range getContainers(getPodSpec(object), "initContainers", "containers") {
+		_, securityContext := mappingValue(container, "securityContext")
+		if securityContext != nil && scalarValue(securityContext, "privileged") == "true" {
+			messages = append(messages, fmt.Sprintf("container '%s' is privileged", scalarValue(container, "name")))
+		}
+	}
+	return messages
+}
+
+// checkProbes checks that the containers of long-running workloads have liveness and readiness probes.
+func checkProbes(object Object) []string {
+	messages := make([]string, 0)
+	if !contains(longRunningKinds, object.Kind) {
+		return messages
+	}
+	for _, container := range getContainers(getPodSpec(object), "containers") {
+		missing := make([]string, 0)
+		for _, key := range []string{"livenessProbe", "readinessProbe"} {
+			if _, probe := mappingValue(container, key); probe == nil {
+				missing = append(missing, strings.TrimSuffix(key, "Probe"))
+			}
+		}
+		if len(missing) > 0 {
+			messages = append(messages, fmt.Sprintf("container '%s' has no %s probe", scalarValue(container, "name"), strings.Join(missing, " or ")))
+		}
+	}
+	return messages
+}
+
+// checkHostPaths checks that pods do not mount hostPath volumes.
+func checkHostPaths(object Object) []string {
+	messages := make([]string, 0)
+	podSpec := getPodSpec(object)
+	if podSpec == nil {
+		return messages
+	}
+	_, volumes := mappingValue(podSpec, "volumes")
+	if volumes == nil || volumes.Kind != yaml.SequenceNode {
+		return messages
+	}
+	for _, volume := range volumes.Content {
+		if _, hostPath := mappingValue(volume, "hostPath"); hostPath != nil {
+			messages = append(messages, fmt.Sprintf("volume '%s' mounts host path '%s'", scalarValue(volume, "name"), scalarValue(hostPath, "path")))
+		}
+	}
+	return messages
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name         string
+		stdout       string
+		suppressions []LintSuppression
+		want         []string
+	}{
+		{
+			name: "should find all rules in a workload",
+			stdout: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox
+      containers:
+      - name: web
+        image: registry.example.com:5000/web:latest
+        securityContext:
+          privileged: true
+        resources:
+          requests:
+            cpu: 100m
+        readinessProbe:
+          httpGet: {path: /healthz, port: 8080}
+      volumes:
+      - name: docker
+        hostPath:
+          path: /var/run/docker.sock
+`,
+			want: []string{
+				"warning: apps/v1 Deployment web/web of release 'web' of app 'web': container 'init' uses image 'busybox' without a tag [image-tag]",
+				"warning: apps/v1 Deployment web/web of release 'web' of app 'web': container 'web' uses image 'registry.example.com:5000/web:latest' with the 'latest' tag [image-tag]",
+				"warning: apps/v1 Deployment web/web of release 'web' of app 'web': container 'web' has no resource limits [resources]",
+				"error: apps/v1 Deployment web/web of release 'web' of app 'web': container 'web' is privileged [privileged]",
+				"warning: apps/v1 Deployment web/web of release 'web' of app 'web': container 'web' has no liveness probe [probes]",
+				"error: apps/v1 Deployment web/web of release 'web' of app 'web': volume 'docker' mounts host path '/var/run/docker.sock' [host-path]",
+			},
+		},
+		{
+			name: "should not find missing probes of jobs and images with digests",
+			stdout: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: backup
+            image: backup@sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+            resources:
+              requests: {cpu: 100m}
+              limits: {cpu: 200m}
+`,
+			want: []string{},
+		},
+		{
+			name: "should not find rules suppressed for objects",
+			stdout: `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: node-exporter
+spec:
+  template:
+    spec:
+      containers:
+      - name: node-exporter
+        image: node-exporter:v1.8.0
+        securityContext:
+          privileged: true
+`,
+			suppressions: []LintSuppression{
+				{Rules: []string{"privileged", "host-path"}, ObjectFilter: ObjectFilter{Kind: "DaemonSet"}},
+				{Rules: []string{"probes"}, ObjectFilter: ObjectFilter{Name: "node-*"}},
+				{Rules: []string{"resources"}, ObjectFilter: ObjectFilter{Kind: "Deployment"}},
+			},
+			want: []string{
+				"warning: apps/v1 DaemonSet node-exporter of release 'web' of app 'web': container 'node-exporter' has no resource requests or limits [resources]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := Release{Name: "web", SourceRules: SourceRules{LintSuppressions: LintSuppressions{SuppressLint: tt.suppressions}}}
+			cfg := &Config{Renderfile: Renderfile{Apps: []App{{Name: "web", Releases: []Release{release}}}}}
+			manifests := GetManifests([]*Render{{AppName: "web", SrcName: "web", SrcType: "release", Stdout: []byte(tt.stdout)}})
+			findings, err := Lint(cfg, manifests)
+			if err != nil {
+				t.Fatalf("Lint() error = %v", err)
+			}
+			got := make([]string, len(findings))
+			for i, finding := range findings {
+				got[i] = finding.String()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLintSeverityAtLeast(t *testing.T) {
+	tests := []struct {
+		severity  string
+		threshold string
+		want      bool
+	}{
+		{severity: LintError, threshold: LintError, want: true},
+		{severity: LintError, threshold: LintWarning, want: true},
+		{severity: LintWarning, threshold: LintError, want: false},
+		{severity: LintWarning, threshold: LintWarning, want: true},
+	}
+	for _, tt := range tests {
+		if got := IsLintSeverityAtLeast(tt.severity, tt.threshold); got != tt.want {
+			t.Errorf("IsLintSeverityAtLeast(%s, %s) = %v, want %v", tt.severity, tt.threshold, got, tt.want)
+		}
+	}
+}
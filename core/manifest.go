@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -21,7 +22,7 @@ type Manifest struct {
 func (m *Manifest) Doc(noBanner bool) string {
 	var header string
 	if !noBanner {
-		header = fmt.Sprintf("#:manifestus render{appName=%s, srcName=%s, srcType=%s}", m.AppName, m.SrcName, m.SrcType)
+		header = m.banner()
 	}
 
 	docs := make([]string, 0)
@@ -31,15 +32,166 @@ func (m *Manifest) Doc(noBanner bool) string {
 	return fmt.Sprintf("%s\n%s", header, strings.Join(docs, "\n---\n"))
 }
 
-// Write writes the manifest to a file in the output directory.
-func (m *Manifest) Write(outputDir string, flatten bool, noBanner bool) (string, error) {
-	p := path.Join(outputDir, getOutputFilePath(m.AppName, m.SrcName, m.SrcType, flatten))
-	if p, err := filepath.Abs(p); err != nil {
-		return p, err
+// banner returns the comment banner identifying the source of the manifest.
+func (m *Manifest) banner() string {
+	return fmt.Sprintf("#:manifestus render{appName=%s, srcName=%s, srcType=%s}", m.AppName, m.SrcName, m.SrcType)
+}
+
+// OutputPath returns the path of the output file of the manifest relative to an output directory, or
+// of the directory of its output files, with a trailing slash, in the resource layout.
+func (m *Manifest) OutputPath(opts OutputOptions) (string, error) {
+	r := LookupRenderer(m.SrcType)
+	if r == nil {
+		return "", fmt.Errorf("no renderer registered for source type '%s'", m.SrcType)
+	}
+	return getOutputPath(r, m.AppName, m.SrcName, opts), nil
+}
+
+// OutputFiles returns the paths of the output files of the manifest relative to an output directory.
+func (m *Manifest) OutputFiles(opts OutputOptions) ([]string, error) {
+	files, err := m.outputFiles(opts)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.path
+	}
+	return paths, nil
+}
+
+// outputFiles returns the output files of the manifest in the layout of the output options. In the resource
+// layout, each object is written to its own file. With an output path template using variables of objects,
+// objects are written to the files of their expanded paths, so objects with the same path share a file.
+func (m *Manifest) outputFiles(opts OutputOptions) ([]outputFile, error) {
+	outputPath, err := m.OutputPath(opts)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := opts.PathTemplates[m.AppName]
+	if !m.isPerObject(opts) {
+		doc, err := m.NormalizedDoc(opts)
+		if err != nil {
+			return nil, err
+		}
+		return []outputFile{{path: outputPath, data: []byte(doc)}}, nil
+	}
+	header := ""
+	if !opts.NoBanner {
+		header = m.banner() + "\n"
+	}
+	objects, err := m.renderedObjects()
+	if err != nil {
+		return nil, err
+	}
+	if n := opts.Normalizations[m.AppName]; !n.isEmpty() {
+		for i := range objects {
+			if objects[i].text, err = normalizeDocument(objects[i].text, n); err != nil {
+				return nil, err
+			}
+		}
+		if n.SortDocuments {
+			slices.SortStableFunc(objects, func(a, b renderedObject) int {
+				return compareObjectKeys(a.key, b.key)
+			})
+		}
+	}
+	files := make([]outputFile, 0)
+	seen := make(map[string]int)
+	keys := make(map[string]ObjectKey)
+	for _, object := range objects {
+		var p string
+		if tmpl != "" {
+			vars := getObjectTemplateVars(object.key)
+			vars["app"], vars["source"], vars["type"] = m.AppName, m.SrcName, m.SrcType
+			p = expandPathTemplate(tmpl, vars)
+			if i, ok := seen[p]; ok {
+				files[i].data = append(files[i].data, []byte("---\n"+object.text+"\n")...)
+				continue
+			}
+		} else {
+			p = path.Join(outputPath, getResourceFileName(object.key))
+			if _, ok := seen[p]; ok {
+				return nil, fmt.Errorf("objects %s and %s of %s '%s' of app '%s' have the same output file %s", keys[p], object.key, m.SrcType, m.SrcName, m.AppName, p)
+			}
+			keys[p] = object.key
+		}
+		seen[p] = len(files)
+		files = append(files, outputFile{path: p, data: []byte(header + object.text + "\n")})
+	}
+	return files, nil
+}
+
+// isPerObject returns true if the objects of the manifest are written to output files of their own.
+func (m *Manifest) isPerObject(opts OutputOptions) bool {
+	if tmpl := opts.PathTemplates[m.AppName]; tmpl != "" {
+		return hasObjectTemplateVars(tmpl)
+	}
+	return opts.Layout == LayoutResource
+}
+
+// renderedObject is a Kubernetes object rendered from the source of a manifest.
+type renderedObject struct {
+	key  ObjectKey
+	text string
+}
+
+// renderedObjects returns the objects rendered from the source of the manifest, with the text of
+// their documents as rendered. Items of Lists have no documents of their own, so they are encoded.
+func (m *Manifest) renderedObjects() ([]renderedObject, error) {
+	rendered := make([]renderedObject, 0)
+	for _, render := range m.Renders {
+		for _, doc := range splitDocuments(string(render.Stdout)) {
+			objects, err := ParseObjects([]byte(doc))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse objects of %s '%s' of app '%s': %w", m.SrcType, m.SrcName, m.AppName, err)
+			}
+			for _, object := range objects {
+				text := strings.TrimSpace(doc)
+				if len(objects) > 1 || isListDocument(doc) {
+					data, err := encodeNode(object.Node)
+					if err != nil {
+						return nil, err
+					}
+					text = strings.TrimSpace(string(data))
+				}
+				rendered = append(rendered, renderedObject{key: object.ObjectKey, text: text})
+			}
+		}
+	}
+	return rendered, nil
+}
+
+// Write writes the manifest to files in the output directory and returns their paths. When objects are
+// written to output files of their own, the existing output files of the manifest are removed first,
+// to remove files of objects no longer rendered.
+func (m *Manifest) Write(outputDir string, opts OutputOptions) ([]string, error) {
+	files, err := m.outputFiles(opts)
+	if err != nil {
+		return nil, err
+	}
+	if m.isPerObject(opts) {
+		outputPath, err := m.OutputPath(opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := RemoveOutputPath(outputDir, outputPath); err != nil {
+			return nil, err
+		}
 	}
-	if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
-		return p, err
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		p, err := filepath.Abs(path.Join(outputDir, file.path))
+		if err != nil {
+			return paths, err
+		}
+		if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+			return paths, err
+		}
+		if err := os.WriteFile(p, file.data, 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, p)
 	}
-	err := os.WriteFile(p, []byte(m.Doc(noBanner)), 0644)
-	return p, err
+	return paths, nil
 }
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// merge returns the normalizations combined with those of a parent.
+func (n Normalize) merge(parent Normalize) Normalize {
+	return Normalize{
+		SortDocuments:          n.SortDocuments || parent.SortDocuments,
+		SortKeys:               n.SortKeys || parent.SortKeys,
+		StripSourceComments:    n.StripSourceComments || parent.StripSourceComments,
+		TrimTrailingWhitespace: n.TrimTrailingWhitespace || parent.TrimTrailingWhitespace,
+		FinalNewline:           n.FinalNewline || parent.FinalNewline,
+	}
+}
+
+// isEmpty returns true if no normalizations are enabled.
+func (n Normalize) isEmpty() bool {
+	return n == Normalize{}
+}
+
+// NormalizedDoc returns the manifest as a single document like Doc, normalized by the normalizations of
+// its app in the output options.
+func (m *Manifest) NormalizedDoc(opts OutputOptions) (string, error) {
+	n := opts.Normalizations[m.AppName]
+	if n.isEmpty() {
+		return m.Doc(opts.NoBanner), nil
+	}
+	docs := make([]string, 0)
+	for _, render := range m.Renders {
+		docs = append(docs, splitDocuments(string(render.Stdout))...)
+	}
+	docs, err := normalizeDocuments(docs, n)
+	if err != nil {
+		return "", err
+	}
+	header := ""
+	if !opts.NoBanner {
+		header = m.banner()
+	}
+	doc := header + "\n" + strings.Join(docs, "\n---\n")
+	if n.FinalNewline {
+		doc = strings.TrimRight(doc, "\n") + "\n"
+	}
+	return doc, nil
+}
+
+// normalizeDocuments returns the documents normalized and trimmed of surrounding whitespace. Documents
+// left without any content are removed.
+func normalizeDocuments(docs []string, n Normalize) ([]string, error) {
+	type keyedDoc struct {
+		key  ObjectKey
+		text string
+	}
+	keyed := make([]keyedDoc, 0, len(docs))
+	for _, doc := range docs {
+		text, err := normalizeDocument(doc, n)
+		if err != nil {
+			return nil, err
+		}
+		if text == "" {
+			continue
+		}
+		var key ObjectKey
+		if n.SortDocuments {
+			objects, err := ParseObjects([]byte(text))
+			if err != nil {
+				return nil, err
+			}
+			if len(objects) > 0 {
+				key = objects[0].ObjectKey
+			}
+		}
+		keyed = append(keyed, keyedDoc{key: key, text: text})
+	}
+	if n.SortDocuments {
+		slices.SortStableFunc(keyed, func(a, b keyedDoc) int {
+			return compareObjectKeys(a.key, b.key)
+		})
+	}
+	normalized := make([]string, len(keyed))
+	for i, doc := range keyed {
+		normalized[i] = doc.text
+	}
+	return normalized, nil
+}
+
+// normalizeDocument returns a document normalized and trimmed of surrounding whitespace.
+func normalizeDocument(doc string, n Normalize) (string, error) {
+	lines := strings.Split(doc, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if n.StripSourceComments && strings.HasPrefix(strings.TrimSpace(line), "# Source: ") {
+			continue
+		}
+		if n.TrimTrailingWhitespace {
+			line = strings.TrimRight(line, " \t\r")
+		}
+		kept = append(kept, line)
+	}
+	doc = strings.TrimSpace(strings.Join(kept, "\n"))
+	if !n.SortKeys || doc == "" {
+		return doc, nil
+	}
+	node := yaml.Node{}
+	if err := yaml.Unmarshal([]byte(doc), &node); err != nil {
+		return "", err
+	}
+	if len(node.Content) == 0 {
+		return doc, nil
+	}
+	sortKeys(&node)
+	data, err := encodeNode(&node)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// sortKeys sorts the keys of the mappings in a node tree.
+func sortKeys(node *yaml.Node) {
+	for _, child := range node.Content {
+		sortKeys(child)
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+	slices.SortStableFunc(pairs, func(a, b [2]*yaml.Node) int {
+		return strings.Compare(a[0].Value, b[0].Value)
+	})
+	for i, pair := range pairs {
+		node.Content[2*i], node.Content[2*i+1] = pair[0], pair[1]
+	}
+}
+
+// compareObjectKeys compares objects by their kind, namespace and name.
+func compareObjectKeys(a, b ObjectKey) int {
+	return cmp.Or(
+		strings.Compare(a.Kind, b.Kind),
+		strings.Compare(a.Namespace, b.Namespace),
+		strings.Compare(a.Name, b.Name),
+	)
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestManifest_NormalizedDoc(t *testing.T) {
+	stdout := `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: web
+spec:
+  ports:
+  - port: 80
+---
+# Source: web/templates/empty.yaml
+---
+# Source: web/templates/configmap.yaml
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  namespace: web
+  name: web
+data:
+  b: "2"
+  a: "1"
+
+
+`
+	tests := []struct {
+		name      string
+		normalize Normalize
+		want      string
+	}{
+		{
+			name:      "should not normalize manifests without normalizations",
+			normalize: Normalize{},
+			want:      "#:manifestus render{appName=web, srcName=web, srcType=release}\n" + strings.TrimSpace(stdout),
+		},
+		{
+			name: "should strip source comments and trailing whitespace and end with a newline",
+			normalize: Normalize{
+				StripSourceComments:    true,
+				TrimTrailingWhitespace: true,
+				FinalNewline:           true,
+			},
+			want: `#:manifestus render{appName=web, srcName=web, srcType=release}
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: web
+spec:
+  ports:
+  - port: 80
+---
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  namespace: web
+  name: web
+data:
+  b: "2"
+  a: "1"
+`,
+		},
+		{
+			name: "should sort documents and keys",
+			normalize: Normalize{
+				SortDocuments:       true,
+				SortKeys:            true,
+				StripSourceComments: true,
+				FinalNewline:        true,
+			},
+			want: `#:manifestus render{appName=web, srcName=web, srcType=release}
+apiVersion: v1
+data:
+  a: "1"
+  b: "2"
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: web
+spec:
+  ports:
+    - port: 80
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manifest{AppName: "web", SrcName: "web", SrcType: "release", Renders: Renders{{Stdout: []byte(stdout)}}}
+			opts := OutputOptions{Normalizations: map[string]Normalize{"web": tt.normalize}}
+			got, err := m.NormalizedDoc(opts)
+			if err != nil {
+				t.Fatalf("NormalizedDoc() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizedDoc() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize_merge(t *testing.T) {
+	app := Normalize{SortKeys: true}
+	renderfile := Normalize{FinalNewline: true}
+	want := Normalize{SortKeys: true, FinalNewline: true}
+	if got := app.merge(renderfile); got != want {
+		t.Errorf("merge() got = %+v, want %+v", got, want)
+	}
+}
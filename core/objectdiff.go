@@ -0,0 +1,307 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Statuses of objects and fields in the differences between two sets of objects.
+const (
+	// ObjectAdded is the status of an object or field only in the new objects.
+	ObjectAdded = "added"
+
+	// ObjectRemoved is the status of an object or field only in the old objects.
+	ObjectRemoved = "removed"
+
+	// ObjectChanged is the status of an object or field with different values in the old and new objects.
+	ObjectChanged = "changed"
+)
+
+// ObjectDiff describes the differences of an object between two sets of objects.
+type ObjectDiff struct {
+	ObjectKey
+
+	// AppName, SrcName and SrcType identify the source rendering the object, if diffed by DiffManifestObjects.
+	AppName string
+	SrcName string
+	SrcType string
+
+	// File is the path of the orphaned output file of the object relative to the output directory, if diffed
+	// by DiffOrphanedObjects.
+	File string
+
+	// Status is the status of the object, one of ObjectAdded, ObjectRemoved or ObjectChanged.
+	Status string
+
+	// Fields are the differences of the fields of a changed object.
+	Fields []FieldDiff
+}
+
+// FieldDiff describes the difference of a field of an object.
+type FieldDiff struct {
+	// Path is the JSON Pointer of the field in the object.
+	Path string
+
+	// Status is the status of the field, one of ObjectAdded, ObjectRemoved or ObjectChanged.
+	Status string
+
+	// Old is the old value of the field in flow style YAML, empty if the field was added.
+	Old string
+
+	// New is the new value of the field in flow style YAML, empty if the field was removed.
+	New string
+}
+
+// DiffObjects compares old and new objects by their keys, and returns the differences of objects
+// added, removed or changed, sorted by key. Fields are compared by value, ignoring the order of
+// mapping keys, their formatting, and null values. Fields matching the ignored JSON Pointers,
+// whose segments may be '*' to match any key or index, are not compared. Objects with the same
+// key are compared in order of occurrence.
+func DiffObjects(oldObjects, newObjects []Object, ignored []string) ([]ObjectDiff, error) {
+	oldIDs, oldByID := objectsByID(oldObjects)
+	newIDs, newByID := objectsByID(newObjects)
+	ignoredPaths := make([][]string, len(ignored))
+	for i, pointer := range ignored {
+		var err error
+		if ignoredPaths[i], err = parsePointer(pointer); err != nil {
+			return nil, err
+		}
+	}
+
+	diffs := make([]ObjectDiff, 0)
+	for _, id := range oldIDs {
+		oldObject := oldByID[id]
+		newObject, ok := newByID[id]
+		if !ok {
+			diffs = append(diffs, ObjectDiff{ObjectKey: id.ObjectKey, Status: ObjectRemoved})
+			continue
+		}
+		fields := make([]FieldDiff, 0)
+		diffNodes(oldObject.Node, newObject.Node, nil, ignoredPaths, &fields)
+		if len(fields) > 0 {
+			diffs = append(diffs, ObjectDiff{ObjectKey: id.ObjectKey, Status: ObjectChanged, Fields: fields})
+		}
+	}
+	for _, id := range newIDs {
+		if _, ok := oldByID[id]; !ok {
+			diffs = append(diffs, ObjectDiff{ObjectKey: id.ObjectKey, Status: ObjectAdded})
+		}
+	}
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].String() < diffs[j].String()
+	})
+	return diffs, nil
+}
+
+// DiffManifestObjects compares the objects in the output files of manifests in an output directory with
+// the objects of the manifests, normalized as they are written, like DiffObjects. Objects are compared per
+// manifest, so objects rendered by more than one source are not duplicates, and the differences of each
+// manifest are returned in order with the app name, source name and source type of the manifest.
+func DiffManifestObjects(manifests []*Manifest, outputDir string, opts OutputOptions, ignored []string) ([]ObjectDiff, error) {
+	diffs := make([]ObjectDiff, 0)
+	for _, manifest := range manifests {
+		outputPath, err := manifest.OutputPath(opts)
+		if err != nil {
+			return nil, err
+		}
+		oldObjects, err := ReadOutputObjects(outputDir, []string{outputPath})
+		if err != nil {
+			return nil, err
+		}
+		doc, err := manifest.NormalizedDoc(opts)
+		if err != nil {
+			return nil, err
+		}
+		newObjects, err := ParseObjects([]byte(doc))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse objects of %s '%s' of app '%s': %w", manifest.SrcType, manifest.SrcName, manifest.AppName, err)
+		}
+		manifestDiffs, err := DiffObjects(oldObjects, newObjects, ignored)
+		if err != nil {
+			return nil, err
+		}
+		for _, diff := range manifestDiffs {
+			diff.AppName, diff.SrcName, diff.SrcType = manifest.AppName, manifest.SrcName, manifest.SrcType
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs, nil
+}
+
+// DiffOrphanedObjects compares the objects in orphaned files in an output directory, as returned by
+// GetOrphanedFiles, with no objects, as no source renders them anymore. The objects are returned as removed in order of the files with their paths.
+func DiffOrphanedObjects(outputDir string, files []string) ([]ObjectDiff, error) {
+	diffs := make([]ObjectDiff, 0)
+	for _, file := range files {
+		oldObjects, err := ReadOutputObjects(outputDir, []string{file})
+		if err != nil {
+			return nil, err
+		}
+		fileDiffs, err := DiffObjects(oldObjects, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, diff := range fileDiffs {
+			diff.File = file
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs, nil
+}
+
+// objectID identifies an object by its key and the number of objects with the same key before it.
+type objectID struct {
+	ObjectKey
+	n int
+}
+
+// objectsByID returns the IDs of objects in order of occurrence, and a mapping of the objects by their IDs.
+func objectsByID(objects []Object) ([]objectID, map[objectID]Object) {
+	ids := make([]objectID, len(objects))
+	byID := make(map[objectID]Object, len(objects))
+	counts := make(map[ObjectKey]int)
+	for i, object := range objects {
+		ids[i] = objectID{ObjectKey: object.ObjectKey, n: counts[object.ObjectKey]}
+		byID[ids[i]] = object
+		counts[object.ObjectKey]++
+	}
+	return ids, byID
+}
+
+// diffNodes collects the differences of two nodes at a path, either of which may be nil if absent.
+func diffNodes(oldNode, newNode *yaml.Node, path []string, ignored [][]string, diffs *[]FieldDiff) {
+	oldNode, newNode = resolveNode(oldNode), resolveNode(newNode)
+	for _, pattern := range ignored {
+		if matchPointer(pattern, path) {
+			return
+		}
+	}
+	switch {
+	case oldNode == nil && newNode == nil:
+		return
+	case oldNode == nil:
+		*diffs = append(*diffs, FieldDiff{Path: formatPointer(path), Status: ObjectAdded, New: formatNode(newNode)})
+	case newNode == nil:
+		*diffs = append(*diffs, FieldDiff{Path: formatPointer(path), Status: ObjectRemoved, Old: formatNode(oldNode)})
+	case oldNode.Kind == yaml.MappingNode && newNode.Kind == yaml.MappingNode:
+		keys := make([]string, 0)
+		values := make(map[string][2]*yaml.Node)
+		for i, node := range []*yaml.Node{oldNode, newNode} {
+			for j := 0; j+1 < len(node.Content); j += 2 {
+				key := node.Content[j].Value
+				pair, ok := values[key]
+				if !ok {
+					keys = append(keys, key)
+				}
+				pair[i] = node.Content[j+1]
+				values[key] = pair
+			}
+		}
+		for _, key := range keys {
+			diffNodes(values[key][0], values[key][1], append(path[:len(path):len(path)], key), ignored, diffs)
+		}
+	case oldNode.Kind == yaml.SequenceNode && newNode.Kind == yaml.SequenceNode:
+		for i := 0; i < max(len(oldNode.Content), len(newNode.Content)); i++ {
+			var oldItem, newItem *yaml.Node
+			if i < len(oldNode.Content) {
+				oldItem = oldNode.Content[i]
+			}
+			if i < len(newNode.Content) {
+				newItem = newNode.Content[i]
+			}
+			diffNodes(oldItem, newItem, append(path[:len(path):len(path)], strconv.Itoa(i)), ignored, diffs)
+		}
+	case oldNode.Kind == yaml.ScalarNode && newNode.Kind == yaml.ScalarNode &&
+		oldNode.ShortTag() == newNode.ShortTag() && scalarEqual(oldNode, newNode):
+		return
+	default:
+		*diffs = append(*diffs, FieldDiff{Path: formatPointer(path), Status: ObjectChanged, Old: formatNode(oldNode), New: formatNode(newNode)})
+	}
+}
+
+// resolveNode returns the node an alias refers to, or nil for absent and null nodes.
+func resolveNode(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node != nil && node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return nil
+	}
+	return node
+}
+
+// scalarEqual tests if two scalar nodes of the same tag have the same value, regardless of their formatting.
+func scalarEqual(a, b *yaml.Node) bool {
+	if a.Value == b.Value {
+		return true
+	}
+	var aValue, bValue any
+	if a.Decode(&aValue) != nil || b.Decode(&bValue) != nil {
+		return false
+	}
+	return aValue == bValue
+}
+
+// formatNode formats a node as flow style YAML on a single line, regardless of its formatting.
+func formatNode(node *yaml.Node) string {
+	flow := copyNode(node)
+	clearStyle(flow)
+	flow.Style = yaml.FlowStyle
+	data, err := yaml.Marshal(flow)
+	if err != nil {
+		return node.Value
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// copyNode returns a deep copy of a node.
+func copyNode(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		copied.Content[i] = copyNode(child)
+	}
+	return &copied
+}
+
+// parsePointer parses a JSON Pointer into its unescaped segments.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer '%s': must start with '/'", pointer)
+	}
+	segments := strings.Split(pointer[1:], "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+	return segments, nil
+}
+
+// formatPointer formats segments of a path as a JSON Pointer.
+func formatPointer(path []string) string {
+	var pointer strings.Builder
+	for _, segment := range path {
+		pointer.WriteString("/")
+		pointer.WriteString(strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1"))
+	}
+	return pointer.String()
+}
+
+// matchPointer tests if a path is at or under the path of a pattern, whose '*' segments match any segment.
+func matchPointer(pattern, path []string) bool {
+	if len(path) < len(pattern) {
+		return false
+	}
+	for i, segment := range pattern {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,316 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseObjects(t *testing.T) {
+	data := `# banner
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+    namespace: default
+- apiVersion: v1
+  kind: Namespace
+  metadata:
+    name: b
+---
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: c
+`
+	objects, err := ParseObjects([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseObjects() error = %v", err)
+	}
+	got := make([]string, len(objects))
+	for i, object := range objects {
+		got[i] = object.String()
+	}
+	want := []string{"v1 ConfigMap default/a", "v1 Namespace b", "apps/v1 Deployment c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseObjects() got = %v, want %v", got, want)
+	}
+
+	if _, err := ParseObjects([]byte("- not an object\n")); err == nil {
+		t.Errorf("ParseObjects() expected error parsing a sequence")
+	}
+}
+
+func TestDiffObjects(t *testing.T) {
+	oldData := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hello
+  namespace: default
+  labels: {app: hello, tier: "web"}
+spec:
+  replicas: 1
+  template:
+    metadata:
+      annotations:
+        checksum/config: abc
+    spec:
+      containers:
+      - name: hello
+        image: hello:1.0
+        ports: [{containerPort: 80}]
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: removed
+  namespace: default
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: same
+  namespace: default
+  annotations: null
+spec:
+  ports:
+  - port: 0x50
+    name: 'http'
+`
+	newData := `apiVersion: v1
+kind: Service
+metadata:
+  namespace: default
+  name: same
+spec:
+  ports:
+  - name: http
+    port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hello
+  namespace: default
+  labels:
+    app: hello
+spec:
+  replicas: 3
+  template:
+    metadata:
+      annotations:
+        checksum/config: def
+    spec:
+      containers:
+      - name: hello
+        image: hello:1.1
+        ports:
+        - containerPort: 80
+        - containerPort: 443
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: added
+  namespace: default
+`
+	oldObjects, err := ParseObjects([]byte(oldData))
+	if err != nil {
+		t.Fatalf("ParseObjects() error = %v", err)
+	}
+	newObjects, err := ParseObjects([]byte(newData))
+	if err != nil {
+		t.Fatalf("ParseObjects() error = %v", err)
+	}
+	got, err := DiffObjects(oldObjects, newObjects, []string{"/spec/template/metadata/annotations/checksum~1config"})
+	if err != nil {
+		t.Fatalf("DiffObjects() error = %v", err)
+	}
+	want := []ObjectDiff{
+		{ObjectKey: ObjectKey{"apps/v1", "Deployment", "default", "hello"}, Status: ObjectChanged, Fields: []FieldDiff{
+			{Path: "/metadata/labels/tier", Status: ObjectRemoved, Old: "web"},
+			{Path: "/spec/replicas", Status: ObjectChanged, Old: "1", New: "3"},
+			{Path: "/spec/template/spec/containers/0/image", Status: ObjectChanged, Old: "hello:1.0", New: "hello:1.1"},
+			{Path: "/spec/template/spec/containers/0/ports/1", Status: ObjectAdded, New: "{containerPort: 443}"},
+		}},
+		{ObjectKey: ObjectKey{"v1", "ConfigMap", "default", "added"}, Status: ObjectAdded},
+		{ObjectKey: ObjectKey{"v1", "ConfigMap", "default", "removed"}, Status: ObjectRemoved},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffObjects() got = %#v, want %#v", got, want)
+	}
+
+	// Wildcard segments of ignored fields match any key or index.
+	got, err = DiffObjects(oldObjects[:1], newObjects[1:2], []string{"/metadata/labels", "/spec/replicas", "/spec/template/*/*/checksum~1config", "/spec/template/spec/containers/*"})
+	if err != nil {
+		t.Fatalf("DiffObjects() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("DiffObjects() got = %+v, want none", got)
+	}
+
+	// Objects with the same key are compared in order of occurrence.
+	got, err = DiffObjects(append(oldObjects[2:], oldObjects[2]), append(newObjects[:1], newObjects[0]), nil)
+	if err != nil {
+		t.Fatalf("DiffObjects() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("DiffObjects() got = %+v, want none", got)
+	}
+	got, err = DiffObjects(append(oldObjects[2:], oldObjects[2]), newObjects[:1], nil)
+	if err != nil {
+		t.Fatalf("DiffObjects() error = %v", err)
+	}
+	want = []ObjectDiff{{ObjectKey: ObjectKey{"v1", "Service", "default", "same"}, Status: ObjectRemoved}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffObjects() got = %#v, want %#v", got, want)
+	}
+}
+
+func TestDiffManifestObjects(t *testing.T) {
+	namespace := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: shared
+`
+	committed := map[string]string{
+		"web": namespace + `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: shared
+data:
+  checksum: committed
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-script
+  namespace: shared
+data:
+  script: |
+    echo web
+  shell: sh
+`,
+		"api": namespace,
+	}
+	stdout := map[string]string{
+		"web": namespace + `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: shared
+data:
+  checksum: rendered
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-script
+  namespace: shared
+data:
+  script: |
+    echo web  
+  shell: sh
+`,
+		"api": namespace + `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: api
+  namespace: shared
+`,
+	}
+	cfg := &Config{Renderfile: Renderfile{
+		Output: Output{Normalize: Normalize{TrimTrailingWhitespace: true}},
+		Apps: []App{
+			{Name: "web", Bundles: []Bundle{{Name: "web", SourceRules: SourceRules{IgnoredDifferences: IgnoredDifferences{IgnoreDifferences: []IgnoreDifference{
+				{JSONPointers: []string{"/data/checksum"}, ObjectFilter: ObjectFilter{Kind: "ConfigMap", Name: "web"}},
+			}}}}}},
+			{Name: "api", Bundles: []Bundle{{Name: "api"}}},
+		},
+	}}
+	opts := OutputOptions{Normalizations: cfg.OutputNormalizations()}
+	renders := []*Render{
+		{AppName: "web", SrcName: "web", SrcType: "bundle", Stdout: []byte(stdout["web"])},
+		{AppName: "api", SrcName: "api", SrcType: "bundle", Stdout: []byte(stdout["api"])},
+	}
+	manifests := GetManifests(renders)
+	outputDir := t.TempDir()
+	for _, manifest := range manifests {
+		outputPath, err := manifest.OutputPath(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(outputDir, outputPath)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, outputPath), []byte(committed[manifest.AppName]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := PreserveIgnoredDifferences(cfg, manifests, outputDir, opts); err != nil {
+		t.Fatalf("PreserveIgnoredDifferences() error = %v", err)
+	}
+	got, err := DiffManifestObjects(manifests, outputDir, opts, nil)
+	if err != nil {
+		t.Fatalf("DiffManifestObjects() error = %v", err)
+	}
+	want := []ObjectDiff{
+		{ObjectKey: ObjectKey{"v1", "ConfigMap", "shared", "api"}, AppName: "api", SrcName: "api", SrcType: "bundle", Status: ObjectAdded},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffManifestObjects() got = %#v, want %#v", got, want)
+	}
+}
+
+func TestDiffOrphanedObjects(t *testing.T) {
+	cfg := &Config{Renderfile: Renderfile{
+		Apps: []App{
+			{Name: "web", Bundles: []Bundle{{Name: "web"}}},
+			{Name: "api", Disabled: true, Bundles: []Bundle{{Name: "api"}}},
+		},
+	}}
+	files := map[string]string{
+		"web/web.bundle.manifest.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n",
+		"web/old.bundle.manifest.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: old\n  namespace: web\n",
+		"api/api.bundle.manifest.yaml": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: api\n",
+		"README.md":                    "# Rendered manifests\n",
+	}
+	outputDir := t.TempDir()
+	for p, data := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(outputDir, p)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, p), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	orphaned, err := GetOrphanedFiles(cfg, outputDir, OutputOptions{})
+	if err != nil {
+		t.Fatalf("GetOrphanedFiles() error = %v", err)
+	}
+	wantOrphaned := []string{"api/api.bundle.manifest.yaml", "web/old.bundle.manifest.yaml"}
+	if !reflect.DeepEqual(orphaned, wantOrphaned) {
+		t.Errorf("GetOrphanedFiles() got = %v, want %v", orphaned, wantOrphaned)
+	}
+	got, err := DiffOrphanedObjects(outputDir, orphaned)
+	if err != nil {
+		t.Fatalf("DiffOrphanedObjects() error = %v", err)
+	}
+	want := []ObjectDiff{
+		{ObjectKey: ObjectKey{"v1", "Namespace", "", "api"}, File: "api/api.bundle.manifest.yaml", Status: ObjectRemoved},
+		{ObjectKey: ObjectKey{"v1", "ConfigMap", "web", "old"}, File: "web/old.bundle.manifest.yaml", Status: ObjectRemoved},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffOrphanedObjects() got = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jsonToManifests converts a JSON value evaluated by a configuration language to YAML manifest
+// documents. The value may be a Kubernetes object, or arrays and objects nesting Kubernetes
+// objects at any depth, which are collected in order. Null values are ignored.
+func jsonToManifests(data []byte) ([]byte, error) {
+	// JSON is parsed as YAML to preserve the order of fields and the literals of numbers.
+	root := yaml.Node{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	objects := make([]*yaml.Node, 0)
+	if len(root.Content) > 0 {
+		if err := collectObjects(root.Content[0], "$", &objects); err != nil {
+			return nil, err
+		}
+	}
+	var manifests bytes.Buffer
+	for i, object := range objects {
+		if i > 0 {
+			manifests.WriteString("---\n")
+		}
+		clearStyle(object)
+		data, err := encodeNode(object)
+		if err != nil {
+			return nil, err
+		}
+		manifests.Write(data)
+	}
+	return manifests.Bytes(), nil
+}
+
+// encodeNode encodes a node as YAML indented by two spaces, like most Kubernetes manifests.
+func encodeNode(node *yaml.Node) ([]byte, error) {
+	var data bytes.Buffer
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return data.Bytes(), nil
+}
+
+// collectObjects collects the Kubernetes objects nested in a node, which is at a JSON path for error messages.
+func collectObjects(node *yaml.Node, at string, objects *[]*yaml.Node) error {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if err := collectObjects(item, fmt.Sprintf("%s[%d]", at, i), objects); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		_, apiVersion := mappingValue(node, "apiVersion")
+		_, kind := mappingValue(node, "kind")
+		if apiVersion != nil && kind != nil {
+			*objects = append(*objects, node)
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := collectObjects(node.Content[i+1], at+"."+node.Content[i].Value, objects); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if node.Tag != "!!null" {
+			return fmt.Errorf("expected Kubernetes objects, got %s value at %s", node.Tag, at)
+		}
+	}
+	return nil
+}
+
+// clearStyle clears the flow and quoting styles of a node tree parsed from JSON, so it is encoded in block style.
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// ObjectKey identifies a Kubernetes object by its API version, kind, namespace and name.
+type ObjectKey struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+}
+
+// String returns the API version, kind and namespaced name of an object.
+func (k ObjectKey) String() string {
+	name := k.Name
+	if k.Namespace != "" {
+		name = k.Namespace + "/" + k.Name
+	}
+	return fmt.Sprintf("%s %s %s", k.APIVersion, k.Kind, name)
+}
+
+// Object is a Kubernetes object parsed from a manifest.
+type Object struct {
+	ObjectKey
+
+	// Node is the mapping node of the object.
+	Node *yaml.Node
+}
+
+// ParseObjects parses the Kubernetes objects in the documents of a manifest. Empty documents
+// are skipped, and the items of List kinds are parsed as separate objects.
+func ParseObjects(data []byte) ([]Object, error) {
+	objects := make([]Object, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := yaml.Node{}
+		if err := decoder.Decode(&doc); errors.Is(err, io.EOF) {
+			return objects, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+			continue
+		}
+		parsed, err := parseObject(doc.Content[0])
+		if err != nil {
+			return nil, fmt.Errorf("document at line %d: %w", doc.Line, err)
+		}
+		objects = append(objects, parsed...)
+	}
+}
+
+// parseObject parses a Kubernetes object from its node, or the objects of its items if it is a List.
+func parseObject(node *yaml.Node) ([]Object, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a Kubernetes object")
+	}
+	object := Object{ObjectKey: ObjectKey{
+		APIVersion: scalarValue(node, "apiVersion"),
+		Kind:       scalarValue(node, "kind"),
+	}, Node: node}
+	if object.APIVersion == "" || object.Kind == "" {
+		return nil, fmt.Errorf("missing apiVersion or kind of Kubernetes object")
+	}
+	if _, items := mappingValue(node, "items"); items != nil && strings.HasSuffix(object.Kind, "List") {
+		objects := make([]Object, 0)
+		for _, item := range items.Content {
+			parsed, err := parseObject(item)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, parsed...)
+		}
+		return objects, nil
+	}
+	if _, metadata := mappingValue(node, "metadata"); metadata != nil {
+		object.Namespace = scalarValue(metadata, "namespace")
+		object.Name = scalarValue(metadata, "name")
+	}
+	return []Object{object}, nil
+}
+
+// scalarValue returns the value of a scalar field of a mapping node, or an empty string if there is none.
+func scalarValue(node *yaml.Node, key string) string {
+	if _, value := mappingValue(node, key); value != nil && value.Kind == yaml.ScalarNode {
+		return value.Value
+	}
+	return ""
+}
+
+// GetRenderObjects parses the Kubernetes objects in the stdout of renders.
+func GetRenderObjects(renders []*Render) ([]Object, error) {
+	objects := make([]Object, 0)
+	for _, render := range renders {
+		parsed, err := ParseObjects(render.Stdout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse objects of %s '%s' of app '%s': %w", render.SrcType, render.SrcName, render.AppName, err)
+		}
+		objects = append(objects, parsed...)
+	}
+	return objects, nil
+}
+
+// ReadOutputObjects parses the Kubernetes objects in output files at paths relative to an output directory,
+// as returned by GetOutputFiles. Missing output files and directories are skipped, as they have no objects.
+func ReadOutputObjects(outputDir string, paths []string) ([]Object, error) {
+	objects := make([]Object, 0)
+	for _, p := range paths {
+		files, err := expandOutputPath(outputDir, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			parsed, err := ParseObjects(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse objects of %s: %w", file, err)
+			}
+			objects = append(objects, parsed...)
+		}
+	}
+	return objects, nil
+}
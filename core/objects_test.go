@@ -0,0 +1,59 @@
+package core
+
+import (
+	"testing"
+)
+
+func Test_jsonToManifests(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "should convert an object",
+			data: `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "hello", "labels": {"enabled": "true"}}}`,
+			want: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: hello
+  labels:
+    enabled: "true"
+`,
+		},
+		{
+			name: "should collect nested objects in order",
+			data: `[{"b": {"apiVersion": "v1", "kind": "Secret"}, "a": null}, {"apiVersion": "v1", "kind": "ConfigMap", "data": {"size": 1234567}}]`,
+			want: `apiVersion: v1
+kind: Secret
+---
+apiVersion: v1
+kind: ConfigMap
+data:
+  size: 1234567
+`,
+		},
+		{
+			name: "should convert nothing",
+			data: `null`,
+			want: ``,
+		},
+		{
+			name:    "should reject other values",
+			data:    `{"items": ["hello"]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonToManifests([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("jsonToManifests() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("jsonToManifests() got = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Layouts of the output files of rendered manifests.
+const (
+	// LayoutSource writes the manifests rendered from a source to a single output file.
+	LayoutSource = "source"
+
+	// LayoutResource writes each object rendered from a source to its own output file in a directory of the source.
+	LayoutResource = "resource"
+)
+
+// Layouts are the valid layouts of the output files of rendered manifests.
+var Layouts = []string{LayoutSource, LayoutResource}
+
+// PathTemplateVars are the variables of output path templates. The 'namespace', 'kind' and 'name'
+// variables are of the objects rendered, so templates using them write objects to different files.
+var PathTemplateVars = []string{"app", "source", "type", "namespace", "kind", "name"}
+
+// FlattenPathTemplate is the output path template writing output files of all apps in the output directory
+// instead of in a directory per app, as the deprecated --flatten flag of the CLI does.
+const FlattenPathTemplate = "{app}-{source}.{type}.manifest.yaml"
+
+// objectTemplateVars are the variables of output path templates of the objects rendered.
+var objectTemplateVars = []string{"namespace", "kind", "name"}
+
+// clusterNamespace is the value of the 'namespace' variable of output path templates for cluster-scoped
+// objects. It is not a valid namespace name, so it cannot be mistaken for the namespace of an object.
+const clusterNamespace = "_cluster"
+
+// EnsureLayoutValid checks if the given output layout is valid.
+func EnsureLayoutValid(layout string) error {
+	if layout != "" && !contains(Layouts, layout) {
+		return fmt.Errorf("invalid output layout '%s' (valid: %s)", layout, strings.Join(Layouts, ", "))
+	}
+	return nil
+}
+
+// OutputOptions are the options of the output files of rendered manifests.
+type OutputOptions struct {
+	// NoBanner omits the comment banner identifying the source of rendered manifests in output files.
+	NoBanner bool
+
+	// Layout is the layout of output files, LayoutSource if empty.
+	Layout string
+
+	// PathTemplates are the output path templates of apps by their names. Apps with an output path
+	// template ignore Layout.
+	PathTemplates map[string]string
+
+	// Normalizations are the normalizations of the output files of apps by their names.
+	Normalizations map[string]Normalize
+
+	// KustomizeIndex is true if kustomization indexes listing the output files are written with them.
+	KustomizeIndex bool
+}
+
+// outputFile is an output file of rendered manifests.
+type outputFile struct {
+	// path is the path of the output file relative to the output directory.
+	path string
+
+	// data is the contents of the output file.
+	data []byte
+}
+
+// getOutputPath returns the path of the output file of a source in the source layout, or of the
+// directory of the output files of the source in the resource layout, with a trailing slash.
+// The directory is named after the output file without its extensions. For apps with an output
+// path template, it returns the expanded template, with '*' for variables of objects.
+func getOutputPath(r Renderer, appName, srcName string, opts OutputOptions) string {
+	if tmpl := opts.PathTemplates[appName]; tmpl != "" {
+		vars := map[string]string{"app": appName, "source": srcName, "type": r.Type()}
+		for _, name := range objectTemplateVars {
+			vars[name] = "*"
+		}
+		return expandPathTemplate(tmpl, vars)
+	}
+	file := getRendererOutputFile(r, appName, srcName)
+	if opts.Layout != LayoutResource {
+		return file
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(file, path.Ext(file)), ".manifest") + "/"
+}
+
+// getResourceFileName returns the name of the output file of an object in the resource layout.
+func getResourceFileName(key ObjectKey) string {
+	parts := []string{strings.ToLower(key.Kind)}
+	if key.Namespace != "" {
+		parts = append(parts, key.Namespace)
+	}
+	parts = append(parts, key.Name)
+	return strings.Join(parts, "-") + ".yaml"
+}
+
+// splitDocuments splits YAML text into its documents on separator lines, without the separators.
+func splitDocuments(data string) []string {
+	docs := make([]string, 0)
+	var doc strings.Builder
+	for _, line := range strings.SplitAfter(data, "\n") {
+		if strings.TrimRight(line, " \r\n") == "---" {
+			docs = append(docs, doc.String())
+			doc.Reset()
+			continue
+		}
+		doc.WriteString(line)
+	}
+	return append(docs, doc.String())
+}
+
+// isListDocument returns true if a YAML document is a Kubernetes List of objects.
+func isListDocument(doc string) bool {
+	var list struct {
+		Kind string `yaml:"kind"`
+	}
+	return yaml.Unmarshal([]byte(doc), &list) == nil && strings.HasSuffix(list.Kind, "List")
+}
+
+// validatePathTemplate checks that an output path template uses only known variables and is
+// the relative path of a file within the output directory.
+func validatePathTemplate(tmpl string) error {
+	for rest := tmpl; ; {
+		start := strings.IndexAny(rest, "{}")
+		if start < 0 {
+			break
+		}
+		if rest[start] == '}' {
+			return fmt.Errorf("unexpected '}' in output path template '%s'", tmpl)
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return fmt.Errorf("unclosed '{' in output path template '%s'", tmpl)
+		}
+		if name := rest[start+1 : start+end]; !contains(PathTemplateVars, name) {
+			return fmt.Errorf("unknown variable '{%s}' in output path template '%s' (valid: {%s})", name, tmpl, strings.Join(PathTemplateVars, "}, {"))
+		}
+		rest = rest[start+end+1:]
+	}
+	if path.IsAbs(tmpl) || strings.HasSuffix(tmpl, "/") || contains(strings.Split(tmpl, "/"), "..") {
+		return fmt.Errorf("output path template '%s' is not the relative path of a file in the output directory", tmpl)
+	}
+	return nil
+}
+
+// expandPathTemplate replaces the variables of an output path template with their values.
+// Variables without values are kept as they are.
+func expandPathTemplate(tmpl string, vars map[string]string) string {
+	pairs := make([]string, 0, 2*len(vars))
+	for name, value := range vars {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
+
+// hasObjectTemplateVars returns true if an output path template uses variables of the objects rendered.
+func hasObjectTemplateVars(tmpl string) bool {
+	for _, name := range objectTemplateVars {
+		if strings.Contains(tmpl, "{"+name+"}") {
+			return true
+		}
+	}
+	return false
+}
+
+// globsOverlap returns true if a path matches both of two output path patterns, where '*' matches any
+// characters but '/', as in the patterns of the output files of objects of output path templates.
+func globsOverlap(a, b string) bool {
+	visited := make(map[[2]int]bool)
+	var match func(i, j int) bool
+	match = func(i, j int) bool {
+		if i == len(a) && j == len(b) {
+			return true
+		}
+		if visited[[2]int{i, j}] {
+			return false
+		}
+		visited[[2]int{i, j}] = true
+		// Characters matched by '*' of both patterns can be left out of the path, so it is
+		// enough for '*' to match nothing or characters of the other pattern.
+		if i < len(a) && a[i] == '*' {
+			if match(i+1, j) || j < len(b) && b[j] != '*' && b[j] != '/' && match(i, j+1) {
+				return true
+			}
+		}
+		if j < len(b) && b[j] == '*' {
+			if match(i, j+1) || i < len(a) && a[i] != '*' && a[i] != '/' && match(i+1, j) {
+				return true
+			}
+		}
+		return i < len(a) && j < len(b) && a[i] != '*' && a[i] == b[j] && match(i+1, j+1)
+	}
+	return match(0, 0)
+}
+
+// getObjectTemplateVars returns the values of the variables of output path templates of an object.
+func getObjectTemplateVars(key ObjectKey) map[string]string {
+	namespace := key.Namespace
+	if namespace == "" {
+		namespace = clusterNamespace
+	}
+	return map[string]string{"namespace": namespace, "kind": strings.ToLower(key.Kind), "name": key.Name}
+}
+
+// expandOutputPath returns the paths of the output files at an output path in an output directory.
+// Output paths with a trailing slash are directories of YAML output files, and output paths with
+// '*' are patterns of output files. Missing directories and files matching no patterns are skipped,
+// but other output paths are returned whether they exist or not.
+func expandOutputPath(outputDir, p string) ([]string, error) {
+	if strings.HasSuffix(p, "/") {
+		entries, err := os.ReadDir(path.Join(outputDir, p))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		files := make([]string, 0)
+		for _, entry := range entries {
+			if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".yaml") {
+				files = append(files, path.Join(outputDir, p, entry.Name()))
+			}
+		}
+		return files, nil
+	}
+	if strings.Contains(p, "*") {
+		matches, err := filepath.Glob(path.Join(outputDir, p))
+		if err != nil {
+			return nil, err
+		}
+		files := make([]string, 0)
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.Mode().IsRegular() {
+				files = append(files, match)
+			}
+		}
+		return files, nil
+	}
+	return []string{path.Join(outputDir, p)}, nil
+}
+
+// RemoveOutputPath removes the output files at an output path in an output directory, as returned
+// by GetOutputFiles. Directories of output files are removed along with all their contents.
+func RemoveOutputPath(outputDir, p string) error {
+	if strings.HasSuffix(p, "/") {
+		return os.RemoveAll(path.Join(outputDir, p))
+	}
+	files, err := expandOutputPath(outputDir, p)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetOrphanedFiles returns the paths of the YAML files in an output directory, relative to it, which are
+// not output files of the sources of the enabled apps of a config, nor GitOps objects or kustomization
+// indexes written for them. These are the files of removed sources and disabled apps, which the check
+// command reports as orphaned.
+func GetOrphanedFiles(cfg *Config, outputDir string, opts OutputOptions) ([]string, error) {
+	owned := make(map[string]bool)
+	outputPaths, err := GetOutputFiles(cfg, cfg.EnabledAppNames(), nil, SrcTypes(), opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, outputPath := range outputPaths {
+		files, err := expandOutputPath(outputDir, outputPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			rel, err := filepath.Rel(outputDir, file)
+			if err != nil {
+				return nil, err
+			}
+			owned[filepath.ToSlash(rel)] = true
+		}
+	}
+	gitOpsFiles, err := getGitOpsObjectFiles(cfg, outputDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	indexFiles, err := getKustomizationIndexes(cfg, outputDir, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range slices.Concat(gitOpsFiles, indexFiles) {
+		owned[file.path] = true
+	}
+	files, err := listFiles(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	orphaned := make([]string, 0)
+	for file := range files {
+		if !owned[file] && (strings.HasSuffix(file, ".yaml") || strings.HasSuffix(file, ".yml")) {
+			orphaned = append(orphaned, file)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned, nil
+}
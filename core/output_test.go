@@ -0,0 +1,258 @@
+package core
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func Test_getResourceFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		key  ObjectKey
+		want string
+	}{
+		{
+			name: "should name namespaced objects by kind, namespace and name",
+			key:  ObjectKey{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "web", Name: "frontend"},
+			want: "deployment-web-frontend.yaml",
+		},
+		{
+			name: "should name cluster-scoped objects by kind and name",
+			key:  ObjectKey{APIVersion: "v1", Kind: "Namespace", Name: "web"},
+			want: "namespace-web.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getResourceFileName(tt.key); got != tt.want {
+				t.Errorf("getResourceFileName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validatePathTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr bool
+	}{
+		{name: "should accept known variables", tmpl: "{app}/{namespace}/{kind}-{name}.{source}.{type}.yaml"},
+		{name: "should reject unknown variables", tmpl: "{app}/{src}.yaml", wantErr: true},
+		{name: "should reject unclosed variables", tmpl: "{app}/{source.yaml", wantErr: true},
+		{name: "should reject unopened variables", tmpl: "{app}/source}.yaml", wantErr: true},
+		{name: "should reject absolute paths", tmpl: "/{app}/{source}.yaml", wantErr: true},
+		{name: "should reject paths outside the output directory", tmpl: "{app}/../{source}.yaml", wantErr: true},
+		{name: "should reject directory paths", tmpl: "{app}/{source}/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validatePathTemplate(tt.tmpl); (err != nil) != tt.wantErr {
+				t.Errorf("validatePathTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_globsOverlap(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{name: "should overlap equal paths", a: "web/a.yaml", b: "web/a.yaml", want: true},
+		{name: "should not overlap different paths", a: "web/a.yaml", b: "web/b.yaml"},
+		{name: "should overlap patterns of sources with prefixed names", a: "web/a-*-*.yaml", b: "web/a-b-*-*.yaml", want: true},
+		{name: "should overlap patterns and paths they match", a: "web/*.yaml", b: "web/a.release.manifest.yaml", want: true},
+		{name: "should overlap patterns matching the same paths", a: "web/*-a.yaml", b: "web/b-*.yaml", want: true},
+		{name: "should not overlap patterns of source directories", a: "web/a/*-*.yaml", b: "web/a-b/*-*.yaml"},
+		{name: "should not overlap patterns across directories", a: "web/*.yaml", b: "web/a/b.yaml"},
+		{name: "should not overlap patterns with different suffixes", a: "web/*.a.yaml", b: "web/*.b.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := globsOverlap(tt.a, tt.b); got != tt.want {
+				t.Errorf("globsOverlap() = %v, want %v", got, tt.want)
+			}
+			if got := globsOverlap(tt.b, tt.a); got != tt.want {
+				t.Errorf("globsOverlap() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManifest_outputFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		stdout  string
+		opts    OutputOptions
+		want    []outputFile
+		wantErr bool
+	}{
+		{
+			name:   "should write a file per source",
+			stdout: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: web\n",
+			opts:   OutputOptions{NoBanner: true},
+			want: []outputFile{
+				{path: "app/web.bundle.manifest.yaml", data: []byte("\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: web")},
+			},
+		},
+		{
+			name: "should write a file per object as rendered",
+			stdout: `# Source: web/namespace.yaml
+apiVersion: v1
+kind: Namespace
+metadata:
+    name: web
+---
+---
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: settings, namespace: web}
+`,
+			opts: OutputOptions{Layout: LayoutResource},
+			want: []outputFile{
+				{path: "app/web.bundle/namespace-web.yaml", data: []byte("#:manifestus render{appName=app, srcName=web, srcType=bundle}\n# Source: web/namespace.yaml\napiVersion: v1\nkind: Namespace\nmetadata:\n    name: web\n")},
+				{path: "app/web.bundle/configmap-web-settings.yaml", data: []byte("#:manifestus render{appName=app, srcName=web, srcType=bundle}\napiVersion: v1\nkind: ConfigMap\nmetadata: {name: settings, namespace: web}\n")},
+			},
+		},
+		{
+			name: "should write a file per item of lists",
+			stdout: `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ServiceAccount
+  metadata:
+    name: web
+    namespace: web
+`,
+			opts: OutputOptions{NoBanner: true, Layout: LayoutResource},
+			want: []outputFile{
+				{path: "app/web.bundle/serviceaccount-web-web.yaml", data: []byte("apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: web\n  namespace: web\n")},
+			},
+		},
+		{
+			name:   "should write a file per source with a path template",
+			stdout: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: web\n",
+			opts:   OutputOptions{NoBanner: true, Layout: LayoutResource, PathTemplates: map[string]string{"app": "{type}/{app}-{source}.yaml"}},
+			want: []outputFile{
+				{path: "bundle/app-web.yaml", data: []byte("\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: web")},
+			},
+		},
+		{
+			name: "should write objects with the same expanded path template to the same file",
+			stdout: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: web
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  namespace: web
+`,
+			opts: OutputOptions{NoBanner: true, PathTemplates: map[string]string{"app": "{app}/{namespace}/{source}.yaml"}},
+			want: []outputFile{
+				{path: "app/_cluster/web.yaml", data: []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: web\n")},
+				{path: "app/web/web.yaml", data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n  namespace: web\n---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: credentials\n  namespace: web\n")},
+			},
+		},
+		{
+			name: "should reject objects with the same output file",
+			stdout: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+---
+apiVersion: example.com/v1
+kind: Namespace
+metadata:
+  name: web
+`,
+			opts:    OutputOptions{Layout: LayoutResource},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manifest{AppName: "app", SrcName: "web", SrcType: "bundle", Renders: Renders{{Stdout: []byte(tt.stdout)}}}
+			got, err := m.outputFiles(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("outputFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("outputFiles() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManifest_Write(t *testing.T) {
+	outputDir := t.TempDir()
+	stale := path.Join(outputDir, "app", "web.bundle", "namespace-old.yaml")
+	if err := os.MkdirAll(path.Dir(stale), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writing in the resource layout replaces the files of objects no longer rendered.
+	m := &Manifest{AppName: "app", SrcName: "web", SrcType: "bundle", Renders: Renders{{Stdout: []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: web\n")}}}
+	opts := OutputOptions{Layout: LayoutResource}
+	paths, err := m.Write(outputDir, opts)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if want := []string{path.Join(outputDir, "app", "web.bundle", "namespace-web.yaml")}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Write() got = %v, want %v", paths, want)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Write() expected stale file %s to be removed", stale)
+	}
+
+	// The objects of the written files are read from the directory of the source.
+	objects, err := ReadOutputObjects(outputDir, []string{"app/web.bundle/", "app/missing.bundle/"})
+	if err != nil {
+		t.Fatalf("ReadOutputObjects() error = %v", err)
+	}
+	if len(objects) != 1 || objects[0].ObjectKey != (ObjectKey{APIVersion: "v1", Kind: "Namespace", Name: "web"}) {
+		t.Errorf("ReadOutputObjects() got = %v", objects)
+	}
+}
+
+func TestReadOutputObjects_pathTemplate(t *testing.T) {
+	outputDir := t.TempDir()
+	m := &Manifest{AppName: "app", SrcName: "web", SrcType: "bundle", Renders: Renders{{Stdout: []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: web\n---\napiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: web\n  namespace: web\n")}}}
+	opts := OutputOptions{PathTemplates: map[string]string{"app": "{app}/{namespace}/{kind}-{name}.{source}.yaml"}}
+	if _, err := m.Write(outputDir, opts); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	// The output path of the source is a pattern matching the output files of its objects.
+	outputPath, err := m.OutputPath(opts)
+	if err != nil {
+		t.Fatalf("OutputPath() error = %v", err)
+	}
+	if want := "app/*/*-*.web.yaml"; outputPath != want {
+		t.Errorf("OutputPath() got = %v, want %v", outputPath, want)
+	}
+	objects, err := ReadOutputObjects(outputDir, []string{outputPath})
+	if err != nil {
+		t.Fatalf("ReadOutputObjects() error = %v", err)
+	}
+	if len(objects) != 2 {
+		t.Errorf("ReadOutputObjects() got = %v, want 2 objects", objects)
+	}
+}
@@ -1,18 +1,101 @@
 package core
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path"
 	"strings"
+	"sync"
 )
 
-// ValidSrcTypes is a mapping of valid source types to their descriptions.
-var ValidSrcTypes = map[string]string{
-	"release":       "Helm chart releases",
-	"kustomization": "Kustomization overlays",
-	"bundle":        "Static non-CRD resources",
-	"crds":          "Static CRD resources",
+func init() {
+	RegisterRenderer(releaseRenderer{})
+	RegisterRenderer(kustomizationRenderer{})
+	RegisterRenderer(bundleRenderer{})
+	RegisterRenderer(crdsRenderer{})
+	RegisterRenderer(jsonnetRenderer{})
+	RegisterRenderer(cueRenderer{})
+	RegisterRenderer(execRenderer{})
+}
+
+// releaseRenderer renders Helm chart releases with 'helm template' or 'helmfile template'.
+type releaseRenderer struct{}
+
+func (releaseRenderer) Type() string        { return "release" }
+func (releaseRenderer) Key() string         { return "releases" }
+func (releaseRenderer) Description() string { return "Helm chart releases" }
+
+func (releaseRenderer) Capabilities() Capabilities {
+	return Capabilities{Cache: true, DryRun: true, Builtin: true, Tools: []string{"helm", "helmfile"}}
+}
+
+func (releaseRenderer) Sources(app *App) ([]Source, error) {
+	return toSources(app.Releases), nil
+}
+
+func (releaseRenderer) Render(appName string, src Source, opts RenderOptions) (Renders, error) {
+	render, err := renderRelease(appName, src.(Release), opts)
+	return Renders{render}, err
+}
+
+// kustomizationRenderer renders local and remote kustomizations with 'kustomize build'.
+type kustomizationRenderer struct{}
+
+func (kustomizationRenderer) Type() string        { return "kustomization" }
+func (kustomizationRenderer) Key() string         { return "kustomizations" }
+func (kustomizationRenderer) Description() string { return "Kustomization overlays" }
+
+func (kustomizationRenderer) Capabilities() Capabilities {
+	return Capabilities{Remote: true, Cache: true, DryRun: true, Builtin: true, Tools: []string{"kustomize"}}
+}
+
+func (kustomizationRenderer) Sources(app *App) ([]Source, error) {
+	return toSources(app.Kustomizations), nil
+}
+
+func (kustomizationRenderer) Render(appName string, src Source, opts RenderOptions) (Renders, error) {
+	render, err := renderKustomization(appName, src.(Kustomization), opts)
+	return Renders{render}, err
+}
+
+// bundleRenderer copies static non-CRD manifests from local paths and remote URLs.
+type bundleRenderer struct{}
+
+func (bundleRenderer) Type() string        { return "bundle" }
+func (bundleRenderer) Key() string         { return "bundles" }
+func (bundleRenderer) Description() string { return "Static non-CRD resources" }
+
+func (bundleRenderer) Capabilities() Capabilities {
+	return Capabilities{Remote: true}
+}
+
+func (bundleRenderer) Sources(app *App) ([]Source, error) {
+	return toSources(app.Bundles), nil
+}
+
+func (bundleRenderer) Render(appName string, src Source, _ RenderOptions) (Renders, error) {
+	return renderBundle(appName, src.(Bundle))
+}
+
+// crdsRenderer copies static CRD manifests from local paths and remote URLs.
+type crdsRenderer struct{}
+
+func (crdsRenderer) Type() string        { return "crds" }
+func (crdsRenderer) Key() string         { return "crds" }
+func (crdsRenderer) Description() string { return "Static CRD resources" }
+
+func (crdsRenderer) Capabilities() Capabilities {
+	return Capabilities{Remote: true}
+}
+
+func (crdsRenderer) Sources(app *App) ([]Source, error) {
+	return toSources(app.CRDs), nil
+}
+
+func (crdsRenderer) Render(appName string, src Source, _ RenderOptions) (Renders, error) {
+	return renderCRDs(appName, src.(CRDs))
 }
 
 // Renders is a collection of Render objects containing states of all attempted
@@ -31,7 +114,7 @@ type Render struct {
 	// For Bundle objects, this is the Bundle name.
 	SrcName string
 
-	// SrcType is the type of the source of the Render. One of "release", "kustomization", or "bundle".
+	// SrcType is the type of the source of the Render, as rendered by its registered Renderer.
 	SrcType string
 
 	// CmdLine is the command line that was executed to render the document.
@@ -51,6 +134,9 @@ type Render struct {
 	// For static manifests, this is nil.
 	Stderr []byte
 
+	// Filtered are the documents of objects removed from the standard output by the filters of the source, if any.
+	Filtered []byte
+
 	// Err is the error that occurred during rendering, if any.
 	Err error
 }
@@ -78,65 +164,216 @@ func (r Render) Msg() string {
 // helmfileName is the default name of a Helmfile.
 const helmfileName = "helmfile.yaml"
 
-// getRendersForApp returns a list of rendered manifests for a named app in the Config.
-func getRendersForApp(app *App, srcNames, srcTypes []string, debug, dryRun bool) (Renders, error) {
-	results := make([]*Render, 0)
-	if contains(srcTypes, "release") {
-		for _, release := range app.Releases {
-			if len(srcNames) > 0 && !contains(srcNames, release.Name) {
-				continue
-			}
-			render, err := renderRelease(app.Name, release, debug, dryRun)
-			if err != nil {
-				return nil, err
-			}
-			results = append(results, render)
+const (
+	// engineBinary is the engine rendering sources by executing an external tool binary.
+	engineBinary = "binary"
+
+	// engineBuiltin is the engine rendering sources in-process with a Go SDK.
+	engineBuiltin = "builtin"
+)
+
+// validEngines are the valid engines of sources of renderers with the Builtin capability.
+var validEngines = []string{engineBinary, engineBuiltin}
+
+// RenderOptions are the options used when rendering sources of apps.
+type RenderOptions struct {
+	// Debug enables debug output of render commands.
+	Debug bool
+
+	// DryRun disables execution of render commands, leaving only their command lines in renders.
+	DryRun bool
+
+	// Jobs is the maximum number of sources rendered concurrently. Values less than 1 render one at a time.
+	Jobs int
+
+	// NoCache disables reuse of cached renders of unchanged sources.
+	NoCache bool
+}
+
+// renderTask is a pending render of a source of an App.
+type renderTask struct {
+	appName string
+	srcName string
+	srcType string
+	render  func() (Renders, error)
+}
+
+// getRenderTasksForApp returns a list of render tasks for sources of a named app in the Config,
+// in order of registration of the renderers of their types. The objects rendered from sources
+// are filtered by the filters of the sources, and transformed by the transforms of the app and
+// the sources.
+func getRenderTasksForApp(app *App, srcNames, srcTypes []string, opts RenderOptions) ([]renderTask, error) {
+	results := make([]renderTask, 0)
+	for _, r := range Renderers() {
+		if !contains(srcTypes, r.Type()) {
+			continue
 		}
-	}
-	if contains(srcTypes, "kustomization") {
-		for _, kustomization := range app.Kustomizations {
-			if len(srcNames) > 0 && !contains(srcNames, kustomization.Name) {
-				continue
-			}
-			render, err := renderKustomization(app.Name, kustomization, dryRun)
-			if err != nil {
-				return nil, err
-			}
-			results = append(results, render)
+		srcs, err := r.Sources(app)
+		if err != nil {
+			return nil, err
 		}
-	}
-	if contains(srcTypes, "bundle") {
-		for _, bundle := range app.Bundles {
-			if len(srcNames) > 0 && !contains(srcNames, bundle.Name) {
+		for _, src := range srcs {
+			if len(srcNames) > 0 && !contains(srcNames, src.SrcName()) {
 				continue
 			}
-			renders, err := renderBundle(app.Name, bundle)
-			if err != nil {
-				return nil, err
-			}
-			results = append(results, renders...)
+			filters := getSourceFilters(src)
+			transform := getSourceTransform(app, src)
+			results = append(results, renderTask{
+				appName: app.Name,
+				srcName: src.SrcName(),
+				srcType: r.Type(),
+				render: func() (Renders, error) {
+					renders, err := r.Render(app.Name, src, opts)
+					if err != nil || opts.DryRun {
+						return renders, err
+					}
+					// Objects are filtered and transformed after rendering, so cached renders are of all
+					// objects untransformed. Objects are filtered first, so rules match them as rendered.
+					if !filters.isEmpty() {
+						if renders, err = filterRenders(renders, filters); err != nil {
+							return nil, err
+						}
+					}
+					if transform.isEmpty() {
+						return renders, nil
+					}
+					return transformRenders(renders, transform)
+				},
+			})
 		}
 	}
-	if contains(srcTypes, "crds") {
-		for _, crd := range app.CRDs {
-			if len(srcNames) > 0 && !contains(srcNames, crd.Name) {
-				continue
-			}
-			renders, err := renderCRDs(app.Name, crd)
-			if err != nil {
-				return nil, err
+	return results, nil
+}
+
+// runRenderTasks runs render tasks with up to jobs tasks running concurrently and
+// returns their renders in task order, so that output does not depend on the
+// number of jobs. Errors of all failed tasks are collected and returned together.
+func runRenderTasks(tasks []renderTask, jobs int) (Renders, error) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	results := make([]Renders, len(tasks))
+	errs := make([]error, len(tasks))
+
+	// Start the workers rendering tasks by their index in the task list.
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < min(jobs, len(tasks)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				task := tasks[index]
+				renders, err := task.render()
+				if err != nil {
+					err = fmt.Errorf("failed to render %s '%s' of app '%s': %w", task.srcType, task.srcName, task.appName, err)
+				}
+				results[index], errs[index] = renders, err
 			}
-			results = append(results, renders...)
-		}
+		}()
 	}
-	return results, nil
+	for index := range tasks {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	// Return the renders of all tasks in order if none failed.
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	renders := make(Renders, 0)
+	for _, result := range results {
+		renders = append(renders, result...)
+	}
+	return renders, nil
+}
+
+// jsonnetRenderer renders Jsonnet programs evaluated in-process with go-jsonnet.
+type jsonnetRenderer struct{}
+
+func (jsonnetRenderer) Type() string        { return "jsonnet" }
+func (jsonnetRenderer) Key() string         { return "jsonnets" }
+func (jsonnetRenderer) Description() string { return "Jsonnet programs" }
+
+func (jsonnetRenderer) Capabilities() Capabilities {
+	return Capabilities{DryRun: true}
+}
+
+func (jsonnetRenderer) Sources(app *App) ([]Source, error) {
+	return toSources(app.Jsonnets), nil
 }
 
-// renderRelease returns render of a Helm chart release.
-func renderRelease(appName string, release Release, debug, dryRun bool) (*Render, error) {
-	// If the release has a chart, render it with 'helm template'.
-	if release.Chart != "" {
-		cmdLine, cmd, stdout, stderr, err := execHelmTemplateCmdline(release.Name, release.Chart, release.Version, release.Values, debug, dryRun)
+func (jsonnetRenderer) Render(appName string, src Source, opts RenderOptions) (Renders, error) {
+	render, err := renderJsonnet(appName, src.(Jsonnet), opts)
+	return Renders{render}, err
+}
+
+// cueRenderer renders CUE packages evaluated in-process with the CUE SDK.
+type cueRenderer struct{}
+
+func (cueRenderer) Type() string        { return "cue" }
+func (cueRenderer) Key() string         { return "cues" }
+func (cueRenderer) Description() string { return "CUE packages" }
+
+func (cueRenderer) Capabilities() Capabilities {
+	return Capabilities{DryRun: true}
+}
+
+func (cueRenderer) Sources(app *App) ([]Source, error) {
+	return toSources(app.Cues), nil
+}
+
+func (cueRenderer) Render(appName string, src Source, opts RenderOptions) (Renders, error) {
+	render, err := renderCue(appName, src.(Cue), opts)
+	return Renders{render}, err
+}
+
+// execRenderer renders manifests with arbitrary user-declared commands.
+type execRenderer struct{}
+
+func (execRenderer) Type() string        { return "exec" }
+func (execRenderer) Key() string         { return "execs" }
+func (execRenderer) Description() string { return "Arbitrary render commands" }
+
+func (execRenderer) Capabilities() Capabilities {
+	return Capabilities{DryRun: true}
+}
+
+func (execRenderer) Sources(app *App) ([]Source, error) {
+	return toSources(app.Execs), nil
+}
+
+func (execRenderer) Render(appName string, src Source, opts RenderOptions) (Renders, error) {
+	render, err := renderExec(appName, src.(Exec), opts)
+	return Renders{render}, err
+}
+
+// renderRelease returns render of a Helm chart release, reusing a cached render if its inputs are unchanged.
+func renderRelease(appName string, release Release, opts RenderOptions) (*Render, error) {
+	helmfile := release.Helmfile
+	if helmfile == "" {
+		helmfile = path.Join(configDir, helmfileName)
+	}
+	key := func() (string, error) {
+		return getReleaseCacheKey(appName, release, helmfile, opts)
+	}
+	return cachedRender(opts, key, func(opts RenderOptions) (*Render, error) {
+		var cmdLine string
+		var cmd *exec.Cmd
+		var stdout, stderr []byte
+		var err error
+		switch {
+		case release.Chart != "" && release.Engine == engineBuiltin:
+			// If the release has a chart and uses the builtin engine, render it in-process with the Helm SDK.
+			cmdLine, stdout, err = execHelmTemplateBuiltin(release, opts.DryRun)
+		case release.Chart != "":
+			// If the release has a chart, render it with 'helm template'.
+			cmdLine, cmd, stdout, stderr, err = execHelmTemplateCmdline(release, opts.Debug, opts.DryRun)
+		default:
+			// Otherwise, render the release with 'helmfile template'.
+			cmdLine, cmd, stdout, stderr, err = execHelmfileTemplateCmd(release.Name, helmfile, opts.Debug, opts.DryRun)
+		}
 		return &Render{
 			AppName: appName,
 			SrcName: release.Name,
@@ -147,39 +384,35 @@ func renderRelease(appName string, release Release, debug, dryRun bool) (*Render
 			Stderr:  stderr,
 			Err:     err,
 		}, err
-	}
+	})
+}
 
-	// Otherwise, render the release with 'helmfile template'.
-	helmfile := release.Helmfile
-	if helmfile == "" {
-		helmfile = path.Join(configDir, helmfileName)
+// renderKustomization renders an App Kustomization object, reusing a cached render if its inputs are unchanged.
+func renderKustomization(appName string, kustomization Kustomization, opts RenderOptions) (*Render, error) {
+	key := func() (string, error) {
+		return getKustomizationCacheKey(appName, kustomization, opts)
 	}
-	cmdLine, cmd, stdout, stderr, err := execHelmfileTemplateCmd(release.Name, helmfile, debug, dryRun)
-	return &Render{
-		AppName: appName,
-		SrcName: release.Name,
-		SrcType: "release",
-		CmdLine: cmdLine,
-		Cmd:     cmd,
-		Stdout:  stdout,
-		Stderr:  stderr,
-		Err:     err,
-	}, err
-}
-
-// renderKustomization renders an App Kustomization object.
-func renderKustomization(appName string, kustomization Kustomization, dryRun bool) (*Render, error) {
-	cmdLine, cmd, stdout, stderr, err := execKustomizeBuildCmd(kustomization.Source, dryRun)
-	return &Render{
-		AppName: appName,
-		SrcName: kustomization.Name,
-		SrcType: "kustomization",
-		CmdLine: cmdLine,
-		Cmd:     cmd,
-		Stdout:  stdout,
-		Stderr:  stderr,
-		Err:     err,
-	}, err
+	return cachedRender(opts, key, func(opts RenderOptions) (*Render, error) {
+		var cmdLine string
+		var cmd *exec.Cmd
+		var stdout, stderr []byte
+		var err error
+		if kustomization.Engine == engineBuiltin {
+			cmdLine, stdout, err = execKustomizeBuildBuiltin(kustomization, opts.DryRun)
+		} else {
+			cmdLine, cmd, stdout, stderr, err = execKustomizeBuildCmd(kustomization, opts.DryRun)
+		}
+		return &Render{
+			AppName: appName,
+			SrcName: kustomization.Name,
+			SrcType: "kustomization",
+			CmdLine: cmdLine,
+			Cmd:     cmd,
+			Stdout:  stdout,
+			Stderr:  stderr,
+			Err:     err,
+		}, err
+	})
 }
 
 // renderBundle renders an App Bundle object.
@@ -268,64 +501,166 @@ func renderCRDs(appName string, crds CRDs) (Renders, error) {
 	return renders, nil
 }
 
-// getHelmfileTemplateCmdline returns a 'helmfile template' line command for a Release.
-func getHelmfileTemplateCmdline(releaseName, helmfile string, debug bool) string {
-	cmdline := fmt.Sprintf("helmfile template --file %s --selector name=%s --skip-deps", helmfile, releaseName)
+// getHelmfileTemplateArgs returns the name and args of a 'helmfile template' command for a Release.
+func getHelmfileTemplateArgs(releaseName, helmfile string, debug bool) []string {
+	args := []string{"helmfile", "template", "--file", helmfile, "--selector", "name=" + releaseName, "--skip-deps"}
 	if debug {
-		cmdline += " --debug"
+		args = append(args, "--debug")
 	}
-	return cmdline
+	return args
 }
 
 // execHelmfileTemplateCmd executes a 'helmfile template' command for a Release and returns its command line, command, stdout, stderr and error.
 func execHelmfileTemplateCmd(releaseName, helmfile string, debug, dryRun bool) (string, *exec.Cmd, []byte, []byte, error) {
-	cmdline := getHelmfileTemplateCmdline(releaseName, helmfile, debug)
+	args := getHelmfileTemplateArgs(releaseName, helmfile, debug)
+	cmdline := formatCmdline(args)
 	if dryRun {
 		return cmdline, nil, nil, nil, nil
 	}
-	cmd, stdout, stderr, exitCode, err := execCmd(cmdline, path.Dir(helmfile))
+	cmd, stdout, stderr, exitCode, err := execArgs(args, path.Dir(helmfile), nil)
 	if exitCode != 0 {
 		err = fmt.Errorf("helmfile template failed with exit code %d: %s", exitCode, string(stderr))
 	}
 	return cmdline, cmd, stdout, stderr, err
 }
 
-// getHelmTemplateCmd returns a 'helm template' command line for a Release.
-func getHelmTemplateCmd(releaseName, chart, version, values string, debug bool) string {
-	cmdline := fmt.Sprintf("helm template %s %s --version %s --values %s", releaseName, chart, version, values)
+// getReleaseChart returns the chart of a Release, resolved relative to the config directory if it is a local chart.
+func getReleaseChart(release Release) string {
+	return resolveLocalPath(release.Chart)
+}
+
+// getReleaseValues returns the values files of a Release, resolved relative to the config directory if they exist there.
+func getReleaseValues(release Release) []string {
+	values := make([]string, len(release.Values))
+	for i, value := range release.Values {
+		values[i] = resolveLocalPath(value)
+	}
+	return values
+}
+
+// getHelmTemplateArgs returns the name and args of a 'helm template' command for a Release.
+func getHelmTemplateArgs(release Release, debug bool) []string {
+	args := []string{"helm", "template", release.Name, getReleaseChart(release)}
+	if release.Version != "" {
+		args = append(args, "--version", release.Version)
+	}
+	if release.Namespace != "" {
+		args = append(args, "--namespace", release.Namespace)
+	}
+	if release.KubeVersion != "" {
+		args = append(args, "--kube-version", release.KubeVersion)
+	}
+	for _, values := range getReleaseValues(release) {
+		args = append(args, "--values", values)
+	}
 	if debug {
-		cmdline += " --debug"
+		args = append(args, "--debug")
 	}
-	return cmdline
+	return args
 }
 
 // execHelmfileTemplateCmdline executes a 'helm template' command for a Release and returns its command line, command, stdout, stderr and error.
-func execHelmTemplateCmdline(releaseName, chart, version, values string, debug, dryRun bool) (string, *exec.Cmd, []byte, []byte, error) {
-	cmdline := getHelmTemplateCmd(releaseName, chart, version, values, debug)
+func execHelmTemplateCmdline(release Release, debug, dryRun bool) (string, *exec.Cmd, []byte, []byte, error) {
+	args := getHelmTemplateArgs(release, debug)
+	cmdline := formatCmdline(args)
 	if dryRun {
 		return cmdline, nil, nil, nil, nil
 	}
-	cmd, stdout, stderr, exitCode, err := execCmd(cmdline, "")
+	cmd, stdout, stderr, exitCode, err := execArgs(args, "", nil)
 	if exitCode != 0 {
 		err = fmt.Errorf("helm template failed with exit code %d: %s", exitCode, string(stderr))
 	}
 	return cmdline, cmd, stdout, stderr, err
 }
 
-// getKustomizeBuildCmdline returns a 'kustomize build' command line for a Kustomization source.
-func getKustomizeBuildCmdline(kustomizationSource string) string {
-	return fmt.Sprintf("kustomize build %s", kustomizationSource)
+// execHelmTemplateBuiltin renders a Release with a local chart in-process with the Helm SDK and returns
+// the equivalent 'helm template' command line, which is diagnostic only, stdout and error.
+func execHelmTemplateBuiltin(release Release, dryRun bool) (string, []byte, error) {
+	cmdline := formatCmdline(getHelmTemplateArgs(release, false))
+	if dryRun {
+		return cmdline, nil, nil
+	}
+	stdout, err := renderHelmChart(release.Name, getReleaseChart(release), release.Namespace, release.KubeVersion, getReleaseValues(release))
+	if err != nil {
+		err = fmt.Errorf("helm template failed in-process: %w", err)
+	}
+	return cmdline, stdout, err
+}
+
+// getKustomizeBuildArgs returns the name and args of a 'kustomize build' command for a Kustomization.
+func getKustomizeBuildArgs(kustomization Kustomization) []string {
+	args := []string{"kustomize", "build", getKustomizationSource(kustomization)}
+	if kustomization.LoadRestrictor != "" {
+		args = append(args, "--load-restrictor", kustomization.LoadRestrictor)
+	}
+	if kustomization.EnableHelm {
+		args = append(args, "--enable-helm")
+	}
+	if kustomization.EnableAlphaPlugins {
+		args = append(args, "--enable-alpha-plugins")
+	}
+	return args
+}
+
+// getKustomizationSource returns the source of a Kustomization, resolved relative to the config directory if it is local.
+func getKustomizationSource(kustomization Kustomization) string {
+	return resolveLocalPath(kustomization.Source)
+}
+
+// getKustomizationDir returns the local directory of a Kustomization, whose source may be a kustomization file.
+func getKustomizationDir(kustomization Kustomization) (string, error) {
+	dir := getKustomizationSource(kustomization)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		dir = path.Dir(dir)
+	}
+	return dir, nil
+}
+
+// getKustomizationComponents returns the components of a Kustomization, resolved relative to the config directory
+// if they exist there.
+func getKustomizationComponents(kustomization Kustomization) []string {
+	components := make([]string, len(kustomization.Components))
+	for i, component := range kustomization.Components {
+		components[i] = resolveLocalPath(component)
+	}
+	return components
 }
 
 // execKustomizeBuildCmd executes a 'kustomize build' command for a Kustomization and returns its command line, command, stdout, stderr and error.
-func execKustomizeBuildCmd(kustomizationSource string, dryRun bool) (string, *exec.Cmd, []byte, []byte, error) {
-	cmdline := getKustomizeBuildCmdline(kustomizationSource)
+func execKustomizeBuildCmd(kustomization Kustomization, dryRun bool) (string, *exec.Cmd, []byte, []byte, error) {
+	args := getKustomizeBuildArgs(kustomization)
+	cmdline := formatCmdline(args)
+	if len(kustomization.Components) > 0 {
+		return cmdline, nil, nil, nil, fmt.Errorf("components require the '%s' engine", engineBuiltin)
+	}
 	if dryRun {
 		return cmdline, nil, nil, nil, nil
 	}
-	cmd, stdout, stderr, exitCode, err := execCmd(cmdline, "")
+	cmd, stdout, stderr, exitCode, err := execArgs(args, "", nil)
 	if exitCode != 0 {
 		err = fmt.Errorf("kustomize build failed with exit code %d: %s", exitCode, string(stderr))
 	}
 	return cmdline, cmd, stdout, stderr, err
 }
+
+// execKustomizeBuildBuiltin builds a local Kustomization in-process with the kustomize API and returns
+// the equivalent 'kustomize build' command line, which is diagnostic only, stdout and error.
+func execKustomizeBuildBuiltin(kustomization Kustomization, dryRun bool) (string, []byte, error) {
+	cmdline := formatCmdline(getKustomizeBuildArgs(kustomization))
+	if dryRun {
+		return cmdline, nil, nil
+	}
+	dir, err := getKustomizationDir(kustomization)
+	if err != nil {
+		return cmdline, nil, fmt.Errorf("the builtin engine only builds local kustomizations: %w", err)
+	}
+	stdout, err := buildKustomization(dir, kustomization)
+	if err != nil {
+		err = fmt.Errorf("kustomize build failed in-process: %w", err)
+	}
+	return cmdline, stdout, err
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_runRenderTasks(t *testing.T) {
+	// newTask returns a task rendering its source name as stdout after a delay, or failing if fail is set.
+	newTask := func(srcName string, delay time.Duration, fail bool) renderTask {
+		return renderTask{
+			appName: "app",
+			srcName: srcName,
+			srcType: "bundle",
+			render: func() (Renders, error) {
+				time.Sleep(delay)
+				if fail {
+					return nil, errors.New("boom")
+				}
+				return Renders{{AppName: "app", SrcName: srcName, SrcType: "bundle", Stdout: []byte(srcName)}}, nil
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		tasks   []renderTask
+		jobs    int
+		want    []string
+		wantErr []string
+	}{
+		{
+			name:  "should render tasks in order one at a time",
+			tasks: []renderTask{newTask("a", 0, false), newTask("b", 0, false), newTask("c", 0, false)},
+			jobs:  0,
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name: "should render tasks in order concurrently",
+			tasks: []renderTask{
+				newTask("a", 30*time.Millisecond, false),
+				newTask("b", 20*time.Millisecond, false),
+				newTask("c", 10*time.Millisecond, false),
+				newTask("d", 0, false),
+			},
+			jobs: 4,
+			want: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:    "should collect errors of all failed tasks",
+			tasks:   []renderTask{newTask("a", 0, true), newTask("b", 0, false), newTask("c", 0, true)},
+			jobs:    2,
+			wantErr: []string{"bundle 'a' of app 'app'", "bundle 'c' of app 'app'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runRenderTasks(tt.tasks, tt.jobs)
+			if (err != nil) != (len(tt.wantErr) > 0) {
+				t.Fatalf("runRenderTasks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(fmt.Sprint(err), want) {
+					t.Errorf("runRenderTasks() error = %v, want it to contain %q", err, want)
+				}
+			}
+			if err != nil {
+				return
+			}
+			docs := make([]string, len(got))
+			for i, render := range got {
+				docs[i] = render.Doc()
+			}
+			if !reflect.DeepEqual(docs, tt.want) {
+				t.Errorf("runRenderTasks() got = %v, want %v", docs, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Source is a source of manifests configured in an App, such as a Release or a Bundle.
+type Source interface {
+	// SrcName returns the name of the source, unique among sources of its type in an App.
+	SrcName() string
+}
+
+// Capabilities describes what a Renderer can do with its sources.
+type Capabilities struct {
+	// Remote is true if sources can be fetched from remote URLs.
+	Remote bool
+
+	// Cache is true if renders of unchanged sources are reused from the render cache.
+	Cache bool
+
+	// DryRun is true if render commands can be previewed without executing them.
+	DryRun bool
+
+	// Builtin is true if sources can set their 'engine' to 'builtin' to be rendered in-process without external tools.
+	Builtin bool
+
+	// Tools are the external tools that must be installed to render sources, if any.
+	Tools []string
+}
+
+// Strings returns the names of the capabilities a Renderer has.
+func (c Capabilities) Strings() []string {
+	names := make([]string, 0)
+	if c.Remote {
+		names = append(names, "remote")
+	}
+	if c.Cache {
+		names = append(names, "cache")
+	}
+	if c.DryRun {
+		names = append(names, "dry-run")
+	}
+	if c.Builtin {
+		names = append(names, "builtin")
+	}
+	return names
+}
+
+// Renderer renders manifests from sources of a single type configured in apps.
+// Renderers are registered by source type with RegisterRenderer, and own the
+// decoding of their sources from apps, the naming of their output files, and
+// the rendering of their sources. Output files of sources are named after their
+// types, unless their Renderer is a RendererWithOutputFile.
+type Renderer interface {
+	// Type returns the source type rendered, such as "release", used to target sources and name output files.
+	Type() string
+
+	// Key returns the key of the list of sources of the type in an App of the config, such as "releases".
+	Key() string
+
+	// Description returns a short description of the source type.
+	Description() string
+
+	// Capabilities returns what the Renderer can do with its sources.
+	Capabilities() Capabilities
+
+	// Sources returns the sources of the type configured in an App.
+	Sources(app *App) ([]Source, error)
+
+	// Render renders manifests from a source of an app.
+	Render(appName string, src Source, opts RenderOptions) (Renders, error)
+}
+
+// RendererWithOutputFile is a Renderer naming the output files of its sources.
+type RendererWithOutputFile interface {
+	Renderer
+
+	// OutputFile returns the path of the output file of manifests rendered from a source of an app, relative
+	// to the output directory. Output path templates of apps take precedence over it, and in the resource
+	// layout, the objects of the source are written to a directory named after it without its extensions.
+	OutputFile(appName, srcName string) string
+}
+
+// getRendererOutputFile returns the path of the output file of manifests rendered from a source of an app by
+// a Renderer, named by the Renderer if it is a RendererWithOutputFile, or after the type of the source otherwise.
+func getRendererOutputFile(r Renderer, appName, srcName string) string {
+	if namer, ok := r.(RendererWithOutputFile); ok {
+		return namer.OutputFile(appName, srcName)
+	}
+	return getOutputFilePath(appName, srcName, r.Type())
+}
+
+// renderers are the registered renderers in order of registration.
+var renderers = make([]Renderer, 0)
+
+// RegisterRenderer registers a Renderer for its source type. It is intended to be called
+// from init functions, and panics if a Renderer is already registered for the same type or key.
+func RegisterRenderer(r Renderer) {
+	for _, registered := range renderers {
+		if registered.Type() == r.Type() || registered.Key() == r.Key() {
+			panic(fmt.Sprintf("renderer already registered for source type '%s' or key '%s'", r.Type(), r.Key()))
+		}
+	}
+	renderers = append(renderers, r)
+}
+
+// Renderers returns all registered renderers in order of registration.
+func Renderers() []Renderer {
+	return append([]Renderer(nil), renderers...)
+}
+
+// LookupRenderer returns the registered Renderer for a source type, or nil if there is none.
+func LookupRenderer(srcType string) Renderer {
+	for _, r := range renderers {
+		if r.Type() == srcType {
+			return r
+		}
+	}
+	return nil
+}
+
+// findSource returns the source of a type with a name in a named app in the Config, or nil if there is none.
+func findSource(cfg *Config, appName, srcType, srcName string) (Source, error) {
+	app := cfg.FindApp(appName)
+	r := LookupRenderer(srcType)
+	if app == nil || r == nil {
+		return nil, nil
+	}
+	srcs, err := r.Sources(app)
+	if err != nil {
+		return nil, err
+	}
+	for _, src := range srcs {
+		if src.SrcName() == srcName {
+			return src, nil
+		}
+	}
+	return nil, nil
+}
+
+// lookupRendererByKey returns the registered Renderer for a key of a source list in an App, or nil if there is none.
+func lookupRendererByKey(key string) Renderer {
+	for _, r := range renderers {
+		if r.Key() == key {
+			return r
+		}
+	}
+	return nil
+}
+
+// SrcTypes returns the sorted source types of all registered renderers.
+func SrcTypes() []string {
+	types := make([]string, len(renderers))
+	for i, r := range renderers {
+		types[i] = r.Type()
+	}
+	sort.Strings(types)
+	return types
+}
+
+// DecodeSources decodes the sources of type T configured in an App under a key not defined
+// by the App struct. It is intended for use in Sources methods of renderers registered
+// outside this package. Unknown fields in the sources are returned as ValidationErrors.
+func DecodeSources[T Source](app *App, key string) ([]Source, error) {
+	node, ok := app.Extensions[key]
+	if !ok {
+		return nil, nil
+	}
+	v := &validator{}
+	v.checkKnownFields(&node, reflect.TypeOf([]T{}))
+	if len(v.errs) > 0 {
+		return nil, v.errs
+	}
+	decoded := make([]T, 0)
+	if err := node.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode %s of app '%s': %w", key, app.Name, err)
+	}
+	return toSources(decoded), nil
+}
+
+// toSources converts a list of sources of a concrete type to a list of Source.
+func toSources[T Source](decoded []T) []Source {
+	srcs := make([]Source, len(decoded))
+	for i, src := range decoded {
+		srcs[i] = src
+	}
+	return srcs
+}
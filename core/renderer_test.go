@@ -0,0 +1,101 @@
+package core
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// greeting is a source of a renderer registered like one outside this package would be.
+type greeting struct {
+	Name    string `yaml:"name"`
+	Message string `yaml:"message"`
+}
+
+func (g greeting) SrcName() string {
+	return g.Name
+}
+
+// greetingRenderer renders a ConfigMap from the message of a greeting.
+type greetingRenderer struct{}
+
+func (greetingRenderer) Type() string               { return "greeting" }
+func (greetingRenderer) Key() string                { return "greetings" }
+func (greetingRenderer) Description() string        { return "Greeting ConfigMaps" }
+func (greetingRenderer) Capabilities() Capabilities { return Capabilities{} }
+
+func (greetingRenderer) Sources(app *App) ([]Source, error) {
+	return DecodeSources[greeting](app, "greetings")
+}
+
+func (greetingRenderer) OutputFile(appName, srcName string) string {
+	return path.Join(appName, "greetings", srcName+".yaml")
+}
+
+func (greetingRenderer) Render(appName string, src Source, _ RenderOptions) (Renders, error) {
+	doc := "kind: ConfigMap\ndata:\n  message: " + src.(greeting).Message
+	return Renders{{AppName: appName, SrcName: src.SrcName(), SrcType: "greeting", Stdout: []byte(doc)}}, nil
+}
+
+func TestRegisterRenderer(t *testing.T) {
+	registered := renderers
+	t.Cleanup(func() { renderers = registered })
+	RegisterRenderer(greetingRenderer{})
+
+	data := `renderfile:
+  schema: v1
+  apps:
+  - name: hello
+    greetings:
+    - name: world
+      message: Hello, World!
+    - name: typo
+      mesage: Hello, Typo!
+`
+	root := yaml.Node{}
+	if err := yaml.Unmarshal([]byte(data), &root); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+	errs := validateConfigNode("renderfile.yaml", &root)
+	if len(errs) != 1 || errs[0].Error() != "renderfile.yaml:9:7: unknown field 'mesage' in greeting" {
+		t.Errorf("validateConfigNode() got = %v", errs)
+	}
+
+	// Fix the typo and render the sources of the registered type.
+	cfg := Config{}
+	if err := yaml.Unmarshal([]byte(strings.Replace(data, "mesage", "message", 1)), &cfg); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+	renders, err := GetRenders(&cfg, []string{"hello"}, nil, []string{"greeting"}, RenderOptions{})
+	if err != nil {
+		t.Fatalf("GetRenders() error = %v", err)
+	}
+	got := make([]string, len(renders))
+	for i, render := range renders {
+		got[i] = render.Doc()
+	}
+	want := []string{
+		"kind: ConfigMap\ndata:\n  message: Hello, World!",
+		"kind: ConfigMap\ndata:\n  message: Hello, Typo!",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRenders() got = %v, want %v", got, want)
+	}
+
+	// Output files of the sources of the registered type are named by its renderer.
+	for layout, want := range map[string][]string{
+		LayoutSource:   {"hello/greetings/world.yaml", "hello/greetings/typo.yaml"},
+		LayoutResource: {"hello/greetings/world/", "hello/greetings/typo/"},
+	} {
+		got, err := GetOutputFiles(&cfg, []string{"hello"}, nil, []string{"greeting"}, OutputOptions{Layout: layout})
+		if err != nil {
+			t.Fatalf("GetOutputFiles() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GetOutputFiles() with layout %s got = %v, want %v", layout, got, want)
+		}
+	}
+}
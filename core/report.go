@@ -0,0 +1,290 @@
+package core
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Statuses of output files in check reports.
+const (
+	// CheckUpToDate is the status of an output file up-to-date with its source.
+	CheckUpToDate = "up-to-date"
+
+	// CheckStale is the status of an output file with different contents than a fresh render of its source.
+	CheckStale = "stale"
+
+	// CheckMissing is the status of an output file of a source missing from the output directory.
+	CheckMissing = "missing"
+
+	// CheckOrphaned is the status of a file in the output directory which is not the output file of any source.
+	CheckOrphaned = "orphaned"
+)
+
+// Formats of check reports.
+const (
+	ReportJSON  = "json"
+	ReportJUnit = "junit"
+	ReportSARIF = "sarif"
+)
+
+// ReportFormats are the valid formats of check reports.
+var ReportFormats = []string{ReportJSON, ReportJUnit, ReportSARIF}
+
+// CheckReport is the result of checking that the output files in an output directory are up-to-date with their sources.
+type CheckReport struct {
+	// OutputDir is the output directory checked.
+	OutputDir string `json:"outputDir"`
+
+	// UpToDate is true if all output files are up-to-date.
+	UpToDate bool `json:"upToDate"`
+
+	// Summary are the numbers of output files by status.
+	Summary map[string]int `json:"summary"`
+
+	// Entries are the output files checked, sorted by path.
+	Entries []CheckEntry `json:"entries"`
+}
+
+// CheckEntry is the result of checking an output file.
+type CheckEntry struct {
+	// AppName is the name of the app of the source of the output file, empty for orphaned and generated files.
+	AppName string `json:"app,omitempty"`
+
+	// SrcName is the name of the source of the output file, empty for orphaned and generated files.
+	SrcName string `json:"source,omitempty"`
+
+	// SrcType is the type of the source of the output file, empty for orphaned and generated files.
+	SrcType string `json:"type,omitempty"`
+
+	// File is the path of the output file relative to the output directory.
+	File string `json:"file"`
+
+	// Status is the status of the output file, one of CheckUpToDate, CheckStale, CheckMissing or CheckOrphaned.
+	Status string `json:"status"`
+
+	// Insertions is the number of lines a fresh render inserts in the output file.
+	Insertions int `json:"insertions"`
+
+	// Deletions is the number of lines a fresh render deletes from the output file.
+	Deletions int `json:"deletions"`
+
+	// Diff is the unified diff of a stale output file.
+	Diff string `json:"diff,omitempty"`
+}
+
+// NewCheckReport returns a report of checking an output directory with the manifests freshly rendered from
+// sources and the differences of the output directory from a directory the manifests were written to.
+// Generated files are other output files written to the directory, such as kustomization indexes.
+func NewCheckReport(outputDir string, manifests []*Manifest, generated []string, diffs []FileDiff, opts OutputOptions) (*CheckReport, error) {
+	diffsByFile := make(map[string]FileDiff, len(diffs))
+	for _, diff := range diffs {
+		diffsByFile[diff.Path] = diff
+	}
+	report := &CheckReport{
+		OutputDir: outputDir,
+		UpToDate:  len(diffs) == 0,
+		Summary:   map[string]int{CheckUpToDate: 0, CheckStale: 0, CheckMissing: 0, CheckOrphaned: 0},
+		Entries:   make([]CheckEntry, 0),
+	}
+	addEntry := func(entry CheckEntry) {
+		if diff, ok := diffsByFile[entry.File]; ok {
+			entry.Status = CheckStale
+			if diff.Status == FileAdded {
+				entry.Status = CheckMissing
+			}
+			entry.Insertions, entry.Deletions, entry.Diff = diff.Insertions, diff.Deletions, diff.Unified
+			delete(diffsByFile, entry.File)
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	for _, manifest := range manifests {
+		files, err := manifest.OutputFiles(opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			addEntry(CheckEntry{AppName: manifest.AppName, SrcName: manifest.SrcName, SrcType: manifest.SrcType, File: file, Status: CheckUpToDate})
+		}
+	}
+	for _, file := range generated {
+		addEntry(CheckEntry{File: file, Status: CheckUpToDate})
+	}
+	// Any remaining differences are files in the output directory not written by any source.
+	for _, diff := range diffsByFile {
+		report.Entries = append(report.Entries, CheckEntry{File: diff.Path, Status: CheckOrphaned, Deletions: diff.Deletions})
+	}
+	sort.Slice(report.Entries, func(i, j int) bool {
+		return report.Entries[i].File < report.Entries[j].File
+	})
+	for _, entry := range report.Entries {
+		report.Summary[entry.Status]++
+	}
+	return report, nil
+}
+
+// Write writes the report to a writer in a format, one of ReportFormats.
+func (r *CheckReport) Write(w io.Writer, format string) error {
+	switch format {
+	case ReportJSON:
+		return writeJSON(w, r)
+	case ReportJUnit:
+		return r.writeJUnit(w)
+	case ReportSARIF:
+		return writeJSON(w, r.sarif())
+	default:
+		return fmt.Errorf("unsupported report format '%s' (valid: %s)", format, strings.Join(ReportFormats, ", "))
+	}
+}
+
+// message returns a human-readable message describing the status of an entry.
+func (e CheckEntry) message() string {
+	switch e.Status {
+	case CheckStale:
+		return fmt.Sprintf("%s is stale: a fresh render inserts %d and deletes %d lines", e.File, e.Insertions, e.Deletions)
+	case CheckMissing:
+		return fmt.Sprintf("%s is missing from the output directory", e.File)
+	case CheckOrphaned:
+		return fmt.Sprintf("%s is not the output file of any source", e.File)
+	default:
+		return fmt.Sprintf("%s is up-to-date", e.File)
+	}
+}
+
+// writeJSON writes a value to a writer as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite is a test suite of a JUnit XML report, with the test cases of an app.
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a test case of a JUnit XML report, with the result of checking an output file.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+// junitFailure is the failure of a test case of a JUnit XML report.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// writeJUnit writes the report as JUnit XML, with a test suite per app and a test case per output file.
+// Orphaned and generated files are reported in test suites of their own.
+func (r *CheckReport) writeJUnit(w io.Writer) error {
+	suites := junitTestSuites{Name: "manifestus check"}
+	suiteIndexes := make(map[string]int)
+	for _, entry := range r.Entries {
+		suiteName := entry.AppName
+		if entry.Status == CheckOrphaned {
+			suiteName = "orphaned"
+		} else if suiteName == "" {
+			suiteName = "generated"
+		}
+		i, ok := suiteIndexes[suiteName]
+		if !ok {
+			i = len(suites.Suites)
+			suiteIndexes[suiteName] = i
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: suiteName})
+		}
+		testCase := junitTestCase{Name: entry.File, ClassName: suiteName}
+		if entry.SrcName != "" {
+			testCase.ClassName = fmt.Sprintf("%s.%s.%s", entry.AppName, entry.SrcType, entry.SrcName)
+		}
+		if entry.Status != CheckUpToDate {
+			testCase.Failure = &junitFailure{Message: entry.message(), Type: entry.Status, Text: entry.Diff}
+			suites.Suites[i].Failures++
+			suites.Failures++
+		}
+		suites.Suites[i].Tests++
+		suites.Tests++
+		suites.Suites[i].Cases = append(suites.Suites[i].Cases, testCase)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// sarifRules are the descriptions of the SARIF rules of output files not up-to-date, by status.
+var sarifRules = map[string]string{
+	CheckStale:    "Output file differs from a fresh render of its source",
+	CheckMissing:  "Output file of a source is missing from the output directory",
+	CheckOrphaned: "File in the output directory is not the output file of any source",
+}
+
+// sarif returns the report as a SARIF 2.1.0 log, with a result per output file not up-to-date.
+func (r *CheckReport) sarif() map[string]any {
+	rules := make([]map[string]any, 0, len(sarifRules))
+	for _, status := range []string{CheckStale, CheckMissing, CheckOrphaned} {
+		rules = append(rules, map[string]any{
+			"id":               status,
+			"shortDescription": map[string]any{"text": sarifRules[status]},
+		})
+	}
+	results := make([]map[string]any, 0)
+	for _, entry := range r.Entries {
+		if entry.Status == CheckUpToDate {
+			continue
+		}
+		result := map[string]any{
+			"ruleId":  entry.Status,
+			"level":   "error",
+			"message": map[string]any{"text": entry.message()},
+			"locations": []map[string]any{{
+				"physicalLocation": map[string]any{
+					"artifactLocation": map[string]any{"uri": path.Join(r.OutputDir, entry.File)},
+				},
+			}},
+		}
+		if entry.SrcName != "" {
+			result["properties"] = map[string]any{"app": entry.AppName, "source": entry.SrcName, "type": entry.SrcType}
+		}
+		results = append(results, result)
+	}
+	return map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []map[string]any{{
+			"tool": map[string]any{
+				"driver": map[string]any{
+					"name":           "manifestus",
+					"version":        Version,
+					"informationUri": "https://github.com/mojochao/manifestus",
+					"rules":          rules,
+				},
+			},
+			"results": results,
+		}},
+	}
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewCheckReport(t *testing.T) {
+	manifests := []*Manifest{
+		{AppName: "app", SrcName: "same", SrcType: "bundle"},
+		{AppName: "app", SrcName: "stale", SrcType: "bundle"},
+		{AppName: "app", SrcName: "missing", SrcType: "crds"},
+	}
+	diffs := []FileDiff{
+		{Path: "app/missing.crds.manifest.yaml", Status: FileAdded, Insertions: 2},
+		{Path: "app/stale.bundle.manifest.yaml", Status: FileModified, Insertions: 1, Deletions: 1, Unified: "--- a\n+++ b\n"},
+		{Path: "kustomization.yaml", Status: FileModified, Insertions: 1, Unified: "--- a\n+++ b\n"},
+		{Path: "old/orphan.yaml", Status: FileRemoved, Deletions: 3},
+	}
+	report, err := NewCheckReport("manifests", manifests, []string{"kustomization.yaml"}, diffs, OutputOptions{})
+	if err != nil {
+		t.Fatalf("NewCheckReport() error = %v", err)
+	}
+	want := &CheckReport{
+		OutputDir: "manifests",
+		Summary:   map[string]int{CheckUpToDate: 1, CheckStale: 2, CheckMissing: 1, CheckOrphaned: 1},
+		Entries: []CheckEntry{
+			{AppName: "app", SrcName: "missing", SrcType: "crds", File: "app/missing.crds.manifest.yaml", Status: CheckMissing, Insertions: 2},
+			{AppName: "app", SrcName: "same", SrcType: "bundle", File: "app/same.bundle.manifest.yaml", Status: CheckUpToDate},
+			{AppName: "app", SrcName: "stale", SrcType: "bundle", File: "app/stale.bundle.manifest.yaml", Status: CheckStale, Insertions: 1, Deletions: 1, Diff: "--- a\n+++ b\n"},
+			{File: "kustomization.yaml", Status: CheckStale, Insertions: 1, Diff: "--- a\n+++ b\n"},
+			{File: "old/orphan.yaml", Status: CheckOrphaned, Deletions: 3},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("NewCheckReport() got = %+v, want %+v", report, want)
+	}
+
+	// JSON reports round-trip.
+	var buf bytes.Buffer
+	if err := report.Write(&buf, ReportJSON); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	decoded := &CheckReport{}
+	if err := json.Unmarshal(buf.Bytes(), decoded); err != nil || !reflect.DeepEqual(decoded, want) {
+		t.Errorf("Write() json got = %s, error = %v", buf.String(), err)
+	}
+
+	// JUnit reports have a failed test case per output file not up-to-date.
+	buf.Reset()
+	if err := report.Write(&buf, ReportJUnit); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, s := range []string{
+		`<testsuites name="manifestus check" tests="5" failures="4">`,
+		`<testcase name="app/same.bundle.manifest.yaml" classname="app.bundle.same"></testcase>`,
+		`<failure message="app/stale.bundle.manifest.yaml is stale: a fresh render inserts 1 and deletes 1 lines" type="stale"><![CDATA[--- a` + "\n",
+		`<testsuite name="orphaned" tests="1" failures="1">`,
+		`<testsuite name="generated" tests="1" failures="1">`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("Write() junit got = %s, want to contain %s", buf.String(), s)
+		}
+	}
+
+	// SARIF reports have a result per output file not up-to-date.
+	buf.Reset()
+	if err := report.Write(&buf, ReportSARIF); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	sarif := struct {
+		Runs []struct {
+			Results []struct {
+				RuleID string `json:"ruleId"`
+			} `json:"results"`
+		} `json:"runs"`
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &sarif); err != nil || len(sarif.Runs) != 1 || len(sarif.Runs[0].Results) != 4 {
+		t.Errorf("Write() sarif got = %s, error = %v", buf.String(), err)
+	}
+
+	if err := report.Write(&buf, "xml"); err == nil {
+		t.Errorf("Write() expected error on unsupported format")
+	}
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ObjectProblem is a problem found in an object rendered from a source.
+type ObjectProblem struct {
+	// AppName is the name of the app of the source.
+	AppName string
+
+	// SrcName is the name of the source.
+	SrcName string
+
+	// SrcType is the type of the source.
+	SrcType string
+
+	// Object is the key of the object.
+	Object ObjectKey
+
+	// Message describes the problem.
+	Message string
+}
+
+// Error returns the object, source and description of the problem.
+func (p ObjectProblem) Error() string {
+	return fmt.Sprintf("%s of %s '%s' of app '%s': %s", p.Object, p.SrcType, p.SrcName, p.AppName, p.Message)
+}
+
+// errUnknownKind is the error of validating objects of kinds without a schema.
+var errUnknownKind = errors.New("unknown kind")
+
+// SchemaValidator validates objects against the JSON schemas of their kinds in a local directory, for a
+// version of Kubernetes. Schemas are looked up in the layouts of the kubernetes-json-schema project,
+// '<dir>/v<version>-standalone-strict/<kind>-<group>-<version>.json' and its '-standalone' and plain
+// variants, where the group is the first label of the API group, omitted for the core group, and of the
+// CRDs-catalog project, '<dir>/<group>/<kind>_<version>.json', for custom resources.
+type SchemaValidator struct {
+	dir         string
+	kubeVersion string
+
+	// schemas are the schemas loaded by their paths, which are nil for schemas not found.
+	schemas map[string]*gojsonschema.Schema
+}
+
+// NewSchemaValidator returns a validator of objects against the JSON schemas in a directory for a version of
+// Kubernetes, like '1.31.0' or 'master'.
+func NewSchemaValidator(dir, kubeVersion string) (*SchemaValidator, error) {
+	if info, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("schema directory %s not found", dir)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("schema directory %s is not a directory", dir)
+	}
+	if kubeVersion != "master" && !strings.HasPrefix(kubeVersion, "v") {
+		kubeVersion = "v" + kubeVersion
+	}
+	return &SchemaValidator{dir: dir, kubeVersion: kubeVersion, schemas: make(map[string]*gojsonschema.Schema)}, nil
+}
+
+// sourceObject is an object rendered from a source of an app.
+type sourceObject struct {
+	Object
+
+	// manifest is the manifest of the source.
+	manifest *Manifest
+}
+
+// problem returns a problem of the object.
+func (o sourceObject) problem(message string) ObjectProblem {
+	return ObjectProblem{
+		AppName: o.manifest.AppName,
+		SrcName: o.manifest.SrcName,
+		SrcType: o.manifest.SrcType,
+		Object:  o.ObjectKey,
+		Message: message,
+	}
+}
+
+// ValidateManifests returns the problems of the objects of manifests not valid against their schemas. Custom
+// resources of kinds defined by CRDs in the manifests are validated against the schemas of the CRDs, and other
+// objects against the schemas of the validator, if any. Objects of kinds without a schema are problems too,
+// unless ignoring unknown kinds.
+func ValidateManifests(manifests []*Manifest, v *SchemaValidator, ignoreUnknownKinds bool) ([]ObjectProblem, error) {
+	objects := make([]sourceObject, 0)
+	for _, manifest := range manifests {
+		parsed, err := GetRenderObjects(manifest.Renders)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range parsed {
+			objects = append(objects, sourceObject{Object: object, manifest: manifest})
+		}
+	}
+	definitions, problems := getCRDDefinitions(objects)
+	for _, object := range objects {
+		group, _ := splitAPIVersion(object.APIVersion)
+		var messages []string
+		var err error
+		if definition, ok := definitions[groupKind{group: group, kind: object.Kind}]; ok {
+			messages, err = validateCustomResource(object.Object, definition)
+		} else if v != nil {
+			messages, err = v.Validate(object.Object)
+			if errors.Is(err, errUnknownKind) {
+				if ignoreUnknownKinds {
+					continue
+				}
+				messages, err = []string{fmt.Sprintf("no schema of kind '%s' in API version '%s' for Kubernetes %s", object.Kind, object.APIVersion, v.kubeVersion)}, nil
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, message := range messages {
+			problems = append(problems, object.problem(message))
+		}
+	}
+	return problems, nil
+}
+
+// Validate returns the messages of the problems of an object not valid against the schema of its kind,
+// or errUnknownKind if there is no schema of its kind.
+func (v *SchemaValidator) Validate(object Object) ([]string, error) {
+	schema, err := v.getSchema(object.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	return validateAgainstSchema(object, schema)
+}
+
+// getSchema returns the schema of the kind of an object, loading it on first use.
+func (v *SchemaValidator) getSchema(key ObjectKey) (*gojsonschema.Schema, error) {
+	for _, p := range v.schemaPaths(key) {
+		if schema, ok := v.schemas[p]; ok {
+			if schema != nil {
+				return schema, nil
+			}
+			continue
+		}
+		if !pathExists(p) {
+			v.schemas[p] = nil
+			continue
+		}
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewReferenceLoader("file://" + filepath.ToSlash(abs)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load schema %s: %w", p, err)
+		}
+		v.schemas[p] = schema
+		return schema, nil
+	}
+	return nil, errUnknownKind
+}
+
+// schemaPaths returns the paths of the schema files of the kind of an object, in order of lookup.
+func (v *SchemaValidator) schemaPaths(key ObjectKey) []string {
+	group, version := splitAPIVersion(key.APIVersion)
+	kind := strings.ToLower(key.Kind)
+	name := kind + "-" + version + ".json"
+	if group != "" {
+		name = kind + "-" + strings.Split(group, ".")[0] + "-" + version + ".json"
+	}
+	paths := make([]string, 0, 4)
+	for _, suffix := range []string{"-standalone-strict", "-standalone", ""} {
+		paths = append(paths, filepath.Join(v.dir, v.kubeVersion+suffix, name))
+	}
+	if group != "" {
+		paths = append(paths, filepath.Join(v.dir, group, kind+"_"+version+".json"))
+	}
+	return paths
+}
+
+// splitAPIVersion returns the group and version of an API version, whose group is empty for the core group.
+func splitAPIVersion(apiVersion string) (string, string) {
+	if group, version, found := strings.Cut(apiVersion, "/"); found {
+		return group, version
+	}
+	return "", apiVersion
+}
+
+// validateAgainstSchema returns the messages of the problems of an object not valid against a schema.
+func validateAgainstSchema(object Object, schema *gojsonschema.Schema) ([]string, error) {
+	var value any
+	if err := object.Node.Decode(&value); err != nil {
+		return nil, err
+	}
+	result, err := schema.Validate(gojsonschema.NewGoLoader(value))
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]string, 0, len(result.Errors()))
+	for _, e := range result.Errors() {
+		messages = append(messages, fmt.Sprintf("%s: %s", e.Field(), e.Description()))
+	}
+	return messages, nil
+}
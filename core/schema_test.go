@@ -0,0 +1,123 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// configMapSchema is a strict schema of ConfigMap objects.
+const configMapSchema = `{
+  "type": "object",
+  "required": ["apiVersion", "kind", "metadata"],
+  "additionalProperties": false,
+  "properties": {
+    "apiVersion": {"type": "string"},
+    "kind": {"type": "string"},
+    "metadata": {"type": "object", "properties": {"name": {"type": "string"}}},
+    "data": {"type": "object", "additionalProperties": {"type": "string"}}
+  }
+}`
+
+// certificateSchema is a schema of cert-manager Certificate objects.
+const certificateSchema = `{
+  "type": "object",
+  "properties": {
+    "spec": {"type": "object", "required": ["secretName"], "properties": {"secretName": {"type": "string"}}}
+  }
+}`
+
+func TestValidateManifests(t *testing.T) {
+	dir := t.TempDir()
+	for p, data := range map[string]string{
+		"v1.31.0-standalone-strict/configmap-v1.json": configMapSchema,
+		"cert-manager.io/certificate_v1.json":         certificateSchema,
+	} {
+		p = filepath.Join(dir, p)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stdout := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: valid
+data:
+  key: value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: invalid
+data:
+  replicas: 3
+extra: true
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web-tls
+spec:
+  dnsNames: [example.com]
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+`
+	manifests := GetManifests([]*Render{{AppName: "web", SrcName: "web", SrcType: "bundle", Stdout: []byte(stdout)}})
+
+	tests := []struct {
+		name               string
+		ignoreUnknownKinds bool
+		want               []string
+	}{
+		{
+			name: "should report invalid objects and objects of unknown kinds",
+			want: []string{
+				"v1 ConfigMap invalid of bundle 'web' of app 'web': (root): Additional property extra is not allowed",
+				"v1 ConfigMap invalid of bundle 'web' of app 'web': data.replicas: Invalid type. Expected: string, given: integer",
+				"cert-manager.io/v1 Certificate web-tls of bundle 'web' of app 'web': spec: secretName is required",
+				"example.com/v1 Widget widget of bundle 'web' of app 'web': no schema of kind 'Widget' in API version 'example.com/v1' for Kubernetes v1.31.0",
+			},
+		},
+		{
+			name:               "should skip objects of unknown kinds if ignored",
+			ignoreUnknownKinds: true,
+			want: []string{
+				"v1 ConfigMap invalid of bundle 'web' of app 'web': (root): Additional property extra is not allowed",
+				"v1 ConfigMap invalid of bundle 'web' of app 'web': data.replicas: Invalid type. Expected: string, given: integer",
+				"cert-manager.io/v1 Certificate web-tls of bundle 'web' of app 'web': spec: secretName is required",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewSchemaValidator(dir, "1.31.0")
+			if err != nil {
+				t.Fatalf("NewSchemaValidator() error = %v", err)
+			}
+			problems, err := ValidateManifests(manifests, v, tt.ignoreUnknownKinds)
+			if err != nil {
+				t.Fatalf("ValidateManifests() error = %v", err)
+			}
+			got := make([]string, len(problems))
+			for i, problem := range problems {
+				got[i] = problem.Error()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateManifests() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSchemaValidator(t *testing.T) {
+	if _, err := NewSchemaValidator(filepath.Join(t.TempDir(), "missing"), "master"); err == nil {
+		t.Errorf("NewSchemaValidator() expected error for missing directory")
+	}
+}
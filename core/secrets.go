@@ -0,0 +1,243 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// secretPatterns are the patterns of well-known credentials, by their descriptions.
+var secretPatterns = []struct {
+	description string
+	pattern     *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----`)},
+	{"AWS access key ID", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"password in URL", regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^/\s:@]+:[^/\s:@$]+@`)},
+}
+
+// credentialKeys are the substrings of lowercase names of fields and environment variables holding credentials.
+var credentialKeys = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "api-key", "credential", "privatekey", "private_key", "accesskey", "access_key"}
+
+// objectNamePattern is the pattern of DNS-1123 subdomain names of Kubernetes objects.
+var objectNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// Thresholds of credentials detected by the entropy of values of fields named like credentials.
+const (
+	minCredentialLength  = 16
+	minCredentialEntropy = 3.5
+)
+
+// SecretFinding is a plaintext secret found in an object rendered from a source.
+type SecretFinding struct {
+	// AppName is the name of the app of the source.
+	AppName string
+
+	// SrcName is the name of the source.
+	SrcName string
+
+	// SrcType is the type of the source.
+	SrcType string
+
+	// Object is the key of the object with the secret.
+	Object ObjectKey
+
+	// Reason describes the secret found, and where it is in the object.
+	Reason string
+}
+
+// Error returns a description of the secret found and where it was found.
+func (f SecretFinding) Error() string {
+	return fmt.Sprintf("plaintext secret in %s of %s '%s' of app '%s': %s", f.Object, f.SrcType, f.SrcName, f.AppName, f.Reason)
+}
+
+// SourceWithSecretGuard is a Source with rules allowing plaintext secrets in the objects rendered from it.
+type SourceWithSecretGuard interface {
+	Source
+
+	// SrcAllowSecrets returns the rules of objects rendered from the source allowed to have plaintext secrets.
+	SrcAllowSecrets() []ObjectFilter
+}
+
+// EnsureNoPlaintextSecrets checks that the objects of manifests have no plaintext secrets, unless allowed
+// by the sources of the manifests. Secrets are Secret objects with data, values of well-known credential
+// patterns, and high-entropy values of fields and environment variables named like credentials, other than
+// references to credentials by object names.
+func EnsureNoPlaintextSecrets(cfg *Config, manifests []*Manifest) error {
+	findings, err := findPlaintextSecrets(cfg, manifests)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, len(findings))
+	for i, finding := range findings {
+		errs[i] = finding
+	}
+	return errors.Join(errs...)
+}
+
+// findPlaintextSecrets returns the plaintext secrets found in the objects of manifests, except objects
+// allowed to have them by the sources of the manifests.
+func findPlaintextSecrets(cfg *Config, manifests []*Manifest) ([]SecretFinding, error) {
+	findings := make([]SecretFinding, 0)
+	for _, manifest := range manifests {
+		objects, err := GetRenderObjects(manifest.Renders)
+		if err != nil {
+			return nil, err
+		}
+		allowed, err := getAllowedSecrets(cfg, manifest)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range objects {
+			if matchesAnyFilter(allowed, object) {
+				continue
+			}
+			if reason := findObjectSecret(object); reason != "" {
+				findings = append(findings, SecretFinding{
+					AppName: manifest.AppName,
+					SrcName: manifest.SrcName,
+					SrcType: manifest.SrcType,
+					Object:  object.ObjectKey,
+					Reason:  reason,
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// getAllowedSecrets returns the rules of objects allowed to have plaintext secrets by the source of a manifest.
+func getAllowedSecrets(cfg *Config, manifest *Manifest) ([]ObjectFilter, error) {
+	src, err := findSource(cfg, manifest.AppName, manifest.SrcType, manifest.SrcName)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := src.(SourceWithSecretGuard); ok {
+		return s.SrcAllowSecrets(), nil
+	}
+	return nil, nil
+}
+
+// findObjectSecret returns a description of the first plaintext secret found in an object, or an empty string.
+func findObjectSecret(object Object) string {
+	if object.APIVersion == "v1" && object.Kind == "Secret" {
+		for _, key := range []string{"data", "stringData"} {
+			if _, data := mappingValue(object.Node, key); data != nil && data.Kind == yaml.MappingNode && len(data.Content) > 0 {
+				return fmt.Sprintf("Secret object with %s", key)
+			}
+		}
+		return ""
+	}
+	return findNodeSecret(object.Node, "", nil)
+}
+
+// findNodeSecret returns a description of the first plaintext secret found in a node, or an empty string.
+// The key is the name of the field or environment variable of the node, and path is its path in the object.
+func findNodeSecret(node *yaml.Node, key string, path []string) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		// Environment variables have their names in a sibling field of their values.
+		envName := ""
+		if _, value := mappingValue(node, "value"); value != nil {
+			envName = scalarValue(node, "name")
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			childKey := node.Content[i].Value
+			if childKey == "value" && envName != "" {
+				childKey = envName
+			}
+			if reason := findNodeSecret(node.Content[i+1], childKey, append(path, node.Content[i].Value)); reason != "" {
+				return reason
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if reason := findNodeSecret(item, key, append(path, strconv.Itoa(i))); reason != "" {
+				return reason
+			}
+		}
+	case yaml.ScalarNode:
+		if node.Tag != "!!str" {
+			return ""
+		}
+		for _, p := range secretPatterns {
+			if p.pattern.MatchString(node.Value) {
+				return fmt.Sprintf("%s at %s", p.description, formatPointer(path))
+			}
+		}
+		if isCredentialKey(key) && !isReferenceKey(key, path) && !isObjectName(node.Value) && isRandomValue(node.Value) {
+			return fmt.Sprintf("high-entropy value of '%s' at %s", key, formatPointer(path))
+		}
+	}
+	return ""
+}
+
+// isCredentialKey returns true if the name of a field or environment variable looks like it holds credentials.
+func isCredentialKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, k := range credentialKeys {
+		if strings.Contains(lower, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// isReferenceKey returns true if the name of a field looks like it references credentials by the name of the
+// object holding them, like 'secretName', 'existingSecret', 'passwordSecretRef', or the 'name' of 'tokenRef'.
+func isReferenceKey(key string, path []string) bool {
+	lower := strings.ToLower(key)
+	if strings.HasSuffix(lower, "name") || strings.HasPrefix(lower, "existing") || strings.HasSuffix(lower, "ref") {
+		return true
+	}
+	return len(path) > 1 && strings.HasSuffix(strings.ToLower(path[len(path)-2]), "ref")
+}
+
+// isObjectName returns true if a value looks like the name of an object, like 'grafana-admin-credentials',
+// rather than a credential. Names are DNS-1123 subdomains of words separated by '-' or '.', so values with
+// long parts mixing letters and digits, like UUIDs and hexadecimal tokens, are not names.
+func isObjectName(value string) bool {
+	if len(value) > 253 || !objectNamePattern.MatchString(value) || !strings.ContainsAny(value, "-.") {
+		return false
+	}
+	for _, part := range strings.FieldsFunc(value, func(c rune) bool { return c == '-' || c == '.' }) {
+		if len(part) >= 8 && strings.ContainsAny(part, "0123456789") && strings.Trim(part, "0123456789") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isRandomValue returns true if a value looks randomly generated, like a password or token, rather than
+// like words, paths or URLs.
+func isRandomValue(value string) bool {
+	if len(value) < minCredentialLength || strings.ContainsAny(value, " \n") ||
+		strings.HasPrefix(value, "/") || strings.HasPrefix(value, "./") || strings.Contains(value, "://") {
+		return false
+	}
+	return shannonEntropy(value) >= minCredentialEntropy
+}
+
+// shannonEntropy returns the Shannon entropy of the characters of a string in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, c := range s {
+		counts[c]++
+		n++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,244 @@
+package core
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// clusterScopedKinds are the kinds of cluster-scoped objects of Kubernetes and its common extensions.
+var clusterScopedKinds = []string{
+	"APIService",
+	"CertificateSigningRequest",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"ComponentStatus",
+	"CSIDriver",
+	"CSINode",
+	"CustomResourceDefinition",
+	"FlowSchema",
+	"IngressClass",
+	"MutatingWebhookConfiguration",
+	"Namespace",
+	"Node",
+	"PersistentVolume",
+	"PriorityClass",
+	"PriorityLevelConfiguration",
+	"RuntimeClass",
+	"StorageClass",
+	"ValidatingAdmissionPolicy",
+	"ValidatingAdmissionPolicyBinding",
+	"ValidatingWebhookConfiguration",
+	"VolumeAttachment",
+}
+
+// SourceWithTransform is a Source with a transform of its own, applied after the transform of its app.
+type SourceWithTransform interface {
+	Source
+
+	// SrcTransform returns the transform of objects rendered from the source.
+	SrcTransform() Transform
+}
+
+// getSourceTransform returns the transform of the objects rendered from a source of an app.
+func getSourceTransform(app *App, src Source) Transform {
+	if s, ok := src.(SourceWithTransform); ok {
+		return s.SrcTransform().merge(app.Transform)
+	}
+	return app.Transform
+}
+
+// merge returns the transform applied after a parent transform, combined with it.
+func (t Transform) merge(parent Transform) Transform {
+	merged := Transform{
+		Namespace:          cmp.Or(t.Namespace, parent.Namespace),
+		Labels:             maps.Clone(parent.Labels),
+		Annotations:        maps.Clone(parent.Annotations),
+		Strip:              append(slices.Clone(parent.Strip), t.Strip...),
+		ClusterScopedKinds: append(slices.Clone(parent.ClusterScopedKinds), t.ClusterScopedKinds...),
+	}
+	if merged.Labels == nil {
+		merged.Labels = make(map[string]string)
+	}
+	maps.Copy(merged.Labels, t.Labels)
+	if merged.Annotations == nil {
+		merged.Annotations = make(map[string]string)
+	}
+	maps.Copy(merged.Annotations, t.Annotations)
+	return merged
+}
+
+// isEmpty returns true if the transform does not change any objects.
+func (t Transform) isEmpty() bool {
+	return t.Namespace == "" && len(t.Labels) == 0 && len(t.Annotations) == 0 && len(t.Strip) == 0
+}
+
+// transformRenders returns copies of renders with the objects in their stdout transformed.
+func transformRenders(renders Renders, t Transform) (Renders, error) {
+	transformed := make(Renders, len(renders))
+	for i, render := range renders {
+		copied := *render
+		if len(bytes.TrimSpace(render.Stdout)) > 0 {
+			stdout, err := transformManifests(render.Stdout, t)
+			if err != nil {
+				return nil, err
+			}
+			copied.Stdout = stdout
+		}
+		transformed[i] = &copied
+	}
+	return transformed, nil
+}
+
+// transformManifests transforms the objects in the documents of manifests and returns the manifests
+// re-encoded. Comments of the documents are kept, but their formatting is normalized.
+func transformManifests(data []byte, t Transform) ([]byte, error) {
+	strip := make([][]string, len(t.Strip))
+	for i, pointer := range t.Strip {
+		segments, err := parsePointer(pointer)
+		if err != nil {
+			return nil, err
+		}
+		strip[i] = segments
+	}
+
+	// Parse the objects of all documents, to know the cluster-scoped kinds defined by their CRDs.
+	docs := make([]*yaml.Node, 0)
+	objects := make([]Object, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := &yaml.Node{}
+		if err := decoder.Decode(doc); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+			continue
+		}
+		parsed, err := parseObject(doc.Content[0])
+		if err != nil {
+			return nil, fmt.Errorf("document at line %d: %w", doc.Line, err)
+		}
+		docs = append(docs, doc)
+		objects = append(objects, parsed...)
+	}
+	clusterKinds := getClusterScopedKinds(objects, t.ClusterScopedKinds)
+
+	for _, object := range objects {
+		for _, segments := range strip {
+			stripField(object.Node, segments)
+		}
+		if len(t.Labels) > 0 {
+			setStringValues(ensureMapping(ensureMapping(object.Node, "metadata"), "labels"), t.Labels)
+		}
+		if len(t.Annotations) > 0 {
+			setStringValues(ensureMapping(ensureMapping(object.Node, "metadata"), "annotations"), t.Annotations)
+		}
+		if t.Namespace != "" && !contains(clusterKinds, object.Kind) {
+			metadata := ensureMapping(object.Node, "metadata")
+			if scalarValue(metadata, "namespace") == "" {
+				setStringValues(metadata, map[string]string{"namespace": t.Namespace})
+			}
+		}
+	}
+
+	encoded := make([]string, len(docs))
+	for i, doc := range docs {
+		data, err := encodeNode(doc)
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = string(data)
+	}
+	return []byte(strings.Join(encoded, "---\n")), nil
+}
+
+// getClusterScopedKinds returns the kinds of cluster-scoped objects of Kubernetes, of the CRDs in
+// a list of objects defining cluster-scoped custom resources, and of a list of extra kinds.
+func getClusterScopedKinds(objects []Object, extraKinds []string) []string {
+	kinds := append(slices.Clone(clusterScopedKinds), extraKinds...)
+	for _, object := range objects {
+		if object.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		_, spec := mappingValue(object.Node, "spec")
+		if spec == nil || scalarValue(spec, "scope") != "Cluster" {
+			continue
+		}
+		if _, names := mappingValue(spec, "names"); names != nil {
+			kinds = append(kinds, scalarValue(names, "kind"))
+		}
+	}
+	return kinds
+}
+
+// stripField removes the fields at the path of segments in a node, whose '*' segments match any key or index.
+func stripField(node *yaml.Node, segments []string) {
+	if len(segments) == 0 {
+		return
+	}
+	segment, rest := segments[0], segments[1:]
+	switch node.Kind {
+	case yaml.MappingNode:
+		content := make([]*yaml.Node, 0, len(node.Content))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if segment == "*" || segment == key.Value {
+				if len(rest) == 0 {
+					continue
+				}
+				stripField(value, rest)
+			}
+			content = append(content, key, value)
+		}
+		node.Content = content
+	case yaml.SequenceNode:
+		content := make([]*yaml.Node, 0, len(node.Content))
+		for i, item := range node.Content {
+			if segment == "*" || segment == strconv.Itoa(i) {
+				if len(rest) == 0 {
+					continue
+				}
+				stripField(item, rest)
+			}
+			content = append(content, item)
+		}
+		node.Content = content
+	}
+}
+
+// ensureMapping returns the mapping value of a key in a mapping node, adding it if missing or not a mapping.
+func ensureMapping(node *yaml.Node, key string) *yaml.Node {
+	keyNode, value := mappingValue(node, key)
+	if value != nil && value.Kind == yaml.MappingNode {
+		return value
+	}
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if keyNode != nil {
+		*value = *mapping
+		return value
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, mapping)
+	return mapping
+}
+
+// setStringValues sets string values of keys in a mapping node, in order of their keys.
+func setStringValues(node *yaml.Node, values map[string]string) {
+	for _, key := range StringKeys(values) {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: values[key]}
+		if _, existing := mappingValue(node, key); existing != nil {
+			*existing = *value
+			continue
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	}
+}
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_transformManifests(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		transform Transform
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "should set the namespace of namespaced objects without one",
+			data: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  namespace: other
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+`,
+			transform: Transform{Namespace: "web"},
+			want: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: web
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  namespace: other
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+`,
+		},
+		{
+			name: "should not set the namespace of cluster-scoped custom resources",
+			data: `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: issuers.example.com
+spec:
+  scope: Cluster
+  names:
+    kind: Issuer
+---
+apiVersion: example.com/v1
+kind: Issuer
+metadata:
+  name: letsencrypt
+---
+apiVersion: example.com/v1
+kind: Cluster
+metadata:
+  name: main
+`,
+			transform: Transform{Namespace: "web", ClusterScopedKinds: []string{"Cluster"}},
+			want: `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: issuers.example.com
+spec:
+  scope: Cluster
+  names:
+    kind: Issuer
+---
+apiVersion: example.com/v1
+kind: Issuer
+metadata:
+  name: letsencrypt
+---
+apiVersion: example.com/v1
+kind: Cluster
+metadata:
+  name: main
+`,
+		},
+		{
+			name: "should strip fields and add labels and annotations",
+			data: `# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    helm.sh/chart: web-1.0.0
+    app: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        resources: {}
+      - name: sidecar
+        resources: {}
+`,
+			transform: Transform{
+				Labels:      map[string]string{"team": "platform", "app": "site"},
+				Annotations: map[string]string{"enabled": "true"},
+				Strip:       []string{"/metadata/labels/helm.sh~1chart", "/spec/template/spec/containers/*/resources"},
+			},
+			want: `# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: site
+    team: platform
+  annotations:
+    enabled: "true"
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+        - name: sidecar
+`,
+		},
+		{
+			name: "should transform items of lists",
+			data: `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ServiceAccount
+  metadata:
+    name: web
+`,
+			transform: Transform{Namespace: "web"},
+			want: `apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: ServiceAccount
+    metadata:
+      name: web
+      namespace: web
+`,
+		},
+		{
+			name:      "should reject invalid JSON Pointers",
+			data:      "apiVersion: v1\nkind: ConfigMap\n",
+			transform: Transform{Strip: []string{"metadata"}},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := transformManifests([]byte(tt.data), tt.transform)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("transformManifests() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("transformManifests() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransform_merge(t *testing.T) {
+	app := Transform{Namespace: "web", Labels: map[string]string{"team": "web", "tier": "frontend"}, Strip: []string{"/status"}}
+	src := Transform{Labels: map[string]string{"team": "platform"}, Strip: []string{"/metadata/labels/chart"}}
+	want := Transform{
+		Namespace:   "web",
+		Labels:      map[string]string{"team": "platform", "tier": "frontend"},
+		Annotations: map[string]string{},
+		Strip:       []string{"/status", "/metadata/labels/chart"},
+	}
+	if got := src.merge(app); !reflect.DeepEqual(got, want) {
+		t.Errorf("merge() got = %+v, want %+v", got, want)
+	}
+}
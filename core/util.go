@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
+	"runtime/debug"
 	"sort"
 	"strings"
 )
@@ -35,13 +37,19 @@ func contains[T comparable](slice []T, item T) bool {
 // execCmd executes a command and returns its result, including stdout, stderr, exit code, and error when executing the command.
 func execCmd(cmdline, workingDir string) (*exec.Cmd, []byte, []byte, int, error) {
 	// split command name and args out of command line
-	parts := strings.Fields(cmdline)
-	cmdName := parts[0]
-	cmdArgs := parts[1:]
-	cmd := exec.Command(cmdName, cmdArgs...)
+	return execArgs(strings.Fields(cmdline), workingDir, nil)
+}
+
+// execArgs executes a command from its name and args in a working directory, with environment variables
+// in 'key=value' form added to those of the current process, and returns its result like execCmd.
+func execArgs(args []string, workingDir string, env []string) (*exec.Cmd, []byte, []byte, int, error) {
+	cmd := exec.Command(args[0], args[1:]...)
 	if workingDir != "" {
 		cmd.Dir = workingDir
 	}
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -52,12 +60,29 @@ func execCmd(cmdline, workingDir string) (*exec.Cmd, []byte, []byte, int, error)
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
 			exitCode = exitErr.ExitCode()
+			err = nil
 		}
-		err = nil
 	}
 	return cmd, stdout.Bytes(), stderr.Bytes(), exitCode, err
 }
 
+// shellQuote quotes a string for a POSIX shell command line, if it contains characters special to the shell.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=+.,:/@%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// formatCmdline returns a shell command line of a command from its name and args, quoted as needed.
+func formatCmdline(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
 // expandTemplate replaces placeholders in a string with values from a map and returns an error if any placeholders are not expanded.
 func expandTemplate(s string, data map[string]string) (string, error) {
 	for key, value := range data {
@@ -75,6 +100,29 @@ func isURL(s string) bool {
 	return strings.HasPrefix(s, "https://")
 }
 
+// resolvePath returns a path resolved relative to the config directory, unless it is empty, absolute or a URL.
+func resolvePath(p string) string {
+	if p == "" || isURL(p) || path.IsAbs(p) {
+		return p
+	}
+	return path.Join(configDir, p)
+}
+
+// resolveLocalPath returns a path resolved relative to the config directory if it exists there, or the path as
+// given otherwise, like the names of remote charts and kustomizations, or paths relative to the working directory.
+func resolveLocalPath(p string) string {
+	if resolved := resolvePath(p); pathExists(resolved) {
+		return resolved
+	}
+	return p
+}
+
+// pathExists tests if a file or directory exists at the given path.
+func pathExists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
+
 // fetchDocument makes an HTTP GET request to the given URL and returns the document data and any error encountered.
 func fetchDocument(url string) ([]byte, error) {
 	resp, err := http.Get(url)
@@ -96,3 +144,17 @@ func fetchDocument(url string) ([]byte, error) {
 func readDocument(path string) ([]byte, error) {
 	return os.ReadFile(path)
 }
+
+// getModuleVersion returns the version of a Go module built into the binary.
+func getModuleVersion(modulePath string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
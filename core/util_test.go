@@ -1,6 +1,9 @@
 package core
 
-import "testing"
+import (
+	"path"
+	"testing"
+)
 
 func Test_expandTemplate(t *testing.T) {
 	type args struct {
@@ -59,3 +62,62 @@ func Test_expandTemplate(t *testing.T) {
 		})
 	}
 }
+
+func Test_resolvePath(t *testing.T) {
+	oldConfigDir := configDir
+	configDir = path.Join("..", "testdata")
+	t.Cleanup(func() { configDir = oldConfigDir })
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "should resolve local charts against the config directory",
+			got:  getReleaseChart(Release{Chart: "charts/hello"}),
+			want: path.Join("..", "testdata", "charts", "hello"),
+		},
+		{
+			name: "should not resolve charts from repositories",
+			got:  getReleaseChart(Release{Chart: "jetstack/cert-manager"}),
+			want: "jetstack/cert-manager",
+		},
+		{
+			name: "should resolve values files against the config directory",
+			got:  getReleaseValues(Release{Values: StringList{"charts/hello-values.yaml"}})[0],
+			want: path.Join("..", "testdata", "charts", "hello-values.yaml"),
+		},
+		{
+			name: "should not resolve values files missing from the config directory",
+			got:  getReleaseValues(Release{Values: StringList{"values/hello.yaml"}})[0],
+			want: "values/hello.yaml",
+		},
+		{
+			name: "should resolve local kustomizations against the config directory",
+			got:  getKustomizationSource(Kustomization{Source: "kustomizations/hello"}),
+			want: path.Join("..", "testdata", "kustomizations", "hello"),
+		},
+		{
+			name: "should not resolve remote kustomizations",
+			got:  getKustomizationSource(Kustomization{Source: "github.com/example/hello//base?ref=v1.0.0"}),
+			want: "github.com/example/hello//base?ref=v1.0.0",
+		},
+		{
+			name: "should resolve components against the config directory",
+			got:  getKustomizationComponents(Kustomization{Components: []string{"kustomizations/components"}})[0],
+			want: path.Join("..", "testdata", "kustomizations", "components"),
+		},
+		{
+			name: "should not resolve absolute paths",
+			got:  resolvePath("/charts/hello"),
+			want: "/charts/hello",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("resolvePath() got = %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
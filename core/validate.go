@@ -0,0 +1,544 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidSchemas is the list of supported Renderfile schema versions.
+var ValidSchemas = []string{"v1"}
+
+// ValidationError represents a problem found in a Renderfile at a line and column.
+type ValidationError struct {
+	Path   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+// Error returns the problem prefixed with the Renderfile path, line and column.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.Path, e.Line, e.Column, e.Msg)
+}
+
+// ValidationErrors is a list of all problems found in a Renderfile.
+type ValidationErrors []*ValidationError
+
+// Error returns all problems found in the Renderfile, one per line.
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// validator collects problems found while validating a Renderfile node tree.
+type validator struct {
+	path string
+	errs ValidationErrors
+}
+
+// addf adds a problem found at the position of a node.
+func (v *validator) addf(node *yaml.Node, format string, args ...any) {
+	v.errs = append(v.errs, &ValidationError{
+		Path:   v.path,
+		Line:   node.Line,
+		Column: node.Column,
+		Msg:    fmt.Sprintf(format, args...),
+	})
+}
+
+// addErr adds an error found at the position of a node, keeping the positions of any ValidationErrors.
+func (v *validator) addErr(node *yaml.Node, err error) {
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		v.addf(node, "%v", err)
+		return
+	}
+	for _, e := range errs {
+		e.Path = v.path
+		v.errs = append(v.errs, e)
+	}
+}
+
+// validateConfigNode validates the node tree of a Renderfile decoded from the given path
+// and returns all problems found, ordered by their position in the Renderfile.
+func validateConfigNode(path string, root *yaml.Node) ValidationErrors {
+	v := &validator{path: path}
+	defer func() {
+		sort.SliceStable(v.errs, func(i, j int) bool {
+			if v.errs[i].Line != v.errs[j].Line {
+				return v.errs[i].Line < v.errs[j].Line
+			}
+			return v.errs[i].Column < v.errs[j].Column
+		})
+	}()
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		v.errs = append(v.errs, &ValidationError{Path: path, Line: 1, Column: 1, Msg: "empty Renderfile"})
+		return v.errs
+	}
+	doc := root.Content[0]
+	v.checkKnownFields(doc, reflect.TypeOf(Config{}))
+	if doc.Kind != yaml.MappingNode {
+		return v.errs
+	}
+
+	_, renderfile := mappingValue(doc, "renderfile")
+	if renderfile == nil {
+		v.addf(doc, "missing required field 'renderfile'")
+		return v.errs
+	}
+	if renderfile.Kind != yaml.MappingNode {
+		return v.errs
+	}
+	v.validateSchema(renderfile)
+	v.validateGitOps(renderfile)
+	v.validateApps(renderfile, v.validateOutput(renderfile))
+	v.validateEnvironments(renderfile)
+	return v.errs
+}
+
+// validateSchema validates the 'schema' field of the '.renderfile' section.
+func (v *validator) validateSchema(renderfile *yaml.Node) {
+	_, schema := mappingValue(renderfile, "schema")
+	if schema == nil {
+		v.addf(renderfile, "missing required field 'schema'")
+		return
+	}
+	if !contains(ValidSchemas, schema.Value) {
+		v.addf(schema, "unsupported schema '%s' (valid: %s)", schema.Value, strings.Join(ValidSchemas, ", "))
+	}
+}
+
+// validateOutput validates the 'output' field of the '.renderfile' section or of an app, and
+// returns its output path template if it is valid.
+func (v *validator) validateOutput(node *yaml.Node) string {
+	_, output := mappingValue(node, "output")
+	if output == nil {
+		return ""
+	}
+	_, tmpl := mappingValue(output, "pathTemplate")
+	if tmpl == nil || tmpl.Value == "" {
+		return ""
+	}
+	if err := validatePathTemplate(tmpl.Value); err != nil {
+		v.addf(tmpl, "%v", err)
+		return ""
+	}
+	return tmpl.Value
+}
+
+// validateGitOps validates the GitOps tool in the 'gitops' field of the '.renderfile' section or of an app.
+func (v *validator) validateGitOps(node *yaml.Node) {
+	_, gitOps := mappingValue(node, "gitops")
+	if gitOps == nil {
+		return
+	}
+	_, tool := mappingValue(gitOps, "tool")
+	if tool != nil && tool.Value != "" && !contains(gitOpsTools, tool.Value) {
+		v.addf(tool, "unsupported gitops tool '%s' (valid: %s)", tool.Value, strings.Join(gitOpsTools, ", "))
+	}
+}
+
+// validateTransform validates the JSON Pointers of fields stripped in the 'transform' field of an app or source.
+func (v *validator) validateTransform(node *yaml.Node) {
+	_, transform := mappingValue(node, "transform")
+	if transform == nil {
+		return
+	}
+	_, strip := mappingValue(transform, "strip")
+	if strip == nil || strip.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, pointer := range strip.Content {
+		if _, err := parsePointer(pointer.Value); err != nil {
+			v.addf(pointer, "%v", err)
+		}
+	}
+}
+
+// validateFilters validates that the rules in the 'include' and 'exclude' fields of a source are not empty,
+// as empty rules match all objects.
+func (v *validator) validateFilters(src *yaml.Node) {
+	for _, key := range []string{"include", "exclude"} {
+		_, rules := mappingValue(src, key)
+		if rules == nil || rules.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, rule := range rules.Content {
+			if rule.Kind == yaml.MappingNode && len(rule.Content) == 0 {
+				v.addf(rule, "empty rule in '%s' matches all objects", key)
+			}
+		}
+	}
+}
+
+// validateLintSuppressions validates the IDs of the lint rules in the 'suppressLint' field of a source.
+func (v *validator) validateLintSuppressions(src *yaml.Node) {
+	_, suppressions := mappingValue(src, "suppressLint")
+	if suppressions == nil || suppressions.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, suppression := range suppressions.Content {
+		_, rules := mappingValue(suppression, "rules")
+		if rules == nil || rules.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, rule := range rules.Content {
+			if !contains(LintRuleIDs(), rule.Value) {
+				v.addf(rule, "unknown lint rule '%s' (valid: %s)", rule.Value, strings.Join(LintRuleIDs(), ", "))
+			}
+		}
+	}
+}
+
+// validateIgnoreDifferences validates that the rules in the 'ignoreDifferences' field of a source have
+// JSON pointers, and that their JSON pointers are valid.
+func (v *validator) validateIgnoreDifferences(src *yaml.Node) {
+	_, rules := mappingValue(src, "ignoreDifferences")
+	if rules == nil || rules.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, rule := range rules.Content {
+		_, pointers := mappingValue(rule, "jsonPointers")
+		if pointers == nil || pointers.Kind != yaml.SequenceNode || len(pointers.Content) == 0 {
+			v.addf(rule, "missing required field 'jsonPointers' in rule of 'ignoreDifferences'")
+			continue
+		}
+		for _, pointer := range pointers.Content {
+			if _, err := parsePointer(pointer.Value); err != nil {
+				v.addf(pointer, "%v in 'ignoreDifferences'", err)
+			}
+		}
+	}
+}
+
+// validateApps validates the names of the apps, the names of their sources, the keys of their
+// source lists, and the output paths of their sources, in the 'apps' field of the '.renderfile'
+// section. Sources of apps without an output path template of their own use the given one, if any.
+func (v *validator) validateApps(renderfile *yaml.Node, pathTemplate string) {
+	_, apps := mappingValue(renderfile, "apps")
+	if apps == nil || apps.Kind != yaml.SequenceNode {
+		return
+	}
+	appFields, _ := yamlFields(reflect.TypeOf(App{}))
+	seenApps := make(map[string]*yaml.Node)
+	seenPaths := make([]seenOutputPath, 0)
+	for _, app := range apps.Content {
+		if app.Kind != yaml.MappingNode {
+			continue
+		}
+		name := v.validateName(app, "app", "", seenApps)
+		v.validateGitOps(app)
+		v.validateTransform(app)
+		appPathTemplate := v.validateOutput(app)
+		if appPathTemplate == "" {
+			appPathTemplate = pathTemplate
+		}
+		for i := 0; i+1 < len(app.Content); i += 2 {
+			key, srcs := app.Content[i], app.Content[i+1]
+			_, isField := appFields[key.Value]
+			r := lookupRendererByKey(key.Value)
+			if r == nil {
+				if !isField {
+					v.addf(key, "unknown field '%s' in app", key.Value)
+				}
+				continue
+			}
+			if !isField {
+				// Sources of renderers registered outside this package are decoded by their renderers.
+				extApp := &App{Name: name, Extensions: map[string]yaml.Node{key.Value: *srcs}}
+				if _, err := r.Sources(extApp); err != nil {
+					v.addErr(srcs, err)
+				}
+			}
+			if srcs.Kind != yaml.SequenceNode {
+				continue
+			}
+			seenSrcs := make(map[string]*yaml.Node)
+			for _, src := range srcs.Content {
+				if src.Kind != yaml.MappingNode {
+					continue
+				}
+				where := fmt.Sprintf(" in '%s' of app '%s'", key.Value, name)
+				// Sources with duplicate names have duplicate output paths, which are not reported again.
+				srcName := v.validateName(src, "source", where, seenSrcs)
+				if isFirstName(app, seenApps) && isFirstName(src, seenSrcs) {
+					v.validateOutputPath(r, name, srcName, where, appPathTemplate, src, &seenPaths)
+				}
+				v.validateTransform(src)
+				v.validateFilters(src)
+				v.validateLintSuppressions(src)
+				v.validateIgnoreDifferences(src)
+				if r.Capabilities().Builtin {
+					v.validateEngine(src)
+				}
+			}
+		}
+	}
+}
+
+// validateEnvironments validates the names of the environments in the 'environments' field of the '.renderfile'
+// section, that they do not set the data of the environment name, and that their apps and the releases of
+// their values files are in the 'apps' field, and the releases have a chart.
+func (v *validator) validateEnvironments(renderfile *yaml.Node) {
+	_, envs := mappingValue(renderfile, "environments")
+	if envs == nil || envs.Kind != yaml.SequenceNode {
+		return
+	}
+	apps := make(map[string]*yaml.Node)
+	if _, appList := mappingValue(renderfile, "apps"); appList != nil && appList.Kind == yaml.SequenceNode {
+		for _, app := range appList.Content {
+			if name := scalarValue(app, "name"); name != "" && apps[name] == nil {
+				apps[name] = app
+			}
+		}
+	}
+	seenEnvs := make(map[string]*yaml.Node)
+	for _, env := range envs.Content {
+		if env.Kind != yaml.MappingNode {
+			continue
+		}
+		name := v.validateName(env, "environment", "", seenEnvs)
+		where := fmt.Sprintf(" of environment '%s'", name)
+		if _, data := mappingValue(env, "data"); data != nil {
+			if key, _ := mappingValue(data, envDataKey); key != nil {
+				v.addf(key, "reserved data key '%s'%s is set to the name of the environment", envDataKey, where)
+			}
+		}
+		if _, appNames := mappingValue(env, "apps"); appNames != nil && appNames.Kind == yaml.SequenceNode {
+			for _, appName := range appNames.Content {
+				if apps[appName.Value] == nil {
+					v.addf(appName, "unknown app '%s' in 'apps'%s", appName.Value, where)
+				}
+			}
+		}
+		_, values := mappingValue(env, "values")
+		if values == nil || values.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(values.Content); i += 2 {
+			appName, releases := values.Content[i], values.Content[i+1]
+			app := apps[appName.Value]
+			if app == nil {
+				v.addf(appName, "unknown app '%s' in 'values'%s", appName.Value, where)
+				continue
+			}
+			if releases.Kind != yaml.MappingNode {
+				continue
+			}
+			for j := 0; j+1 < len(releases.Content); j += 2 {
+				releaseName := releases.Content[j]
+				release := findNamedNode(app, "releases", releaseName.Value)
+				if release == nil {
+					v.addf(releaseName, "unknown release '%s' of app '%s' in 'values'%s", releaseName.Value, appName.Value, where)
+				} else if scalarValue(release, "chart") == "" {
+					v.addf(releaseName, "release '%s' of app '%s' in 'values'%s has no chart", releaseName.Value, appName.Value, where)
+				}
+			}
+		}
+	}
+}
+
+// findNamedNode returns the mapping node with the given name in a list field of a mapping node, if any.
+func findNamedNode(node *yaml.Node, key, name string) *yaml.Node {
+	_, list := mappingValue(node, key)
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, item := range list.Content {
+		if scalarValue(item, "name") == name {
+			return item
+		}
+	}
+	return nil
+}
+
+// validateName validates that a mapping node has a unique, non-empty 'name' field and returns it.
+// The what and where arguments describe the node and its location in problems found.
+func (v *validator) validateName(node *yaml.Node, what, where string, seen map[string]*yaml.Node) string {
+	_, name := mappingValue(node, "name")
+	if name == nil || name.Value == "" {
+		v.addf(node, "missing required field 'name' in %s%s", what, where)
+		return ""
+	}
+	if first, ok := seen[name.Value]; ok {
+		v.addf(name, "duplicate %s name '%s'%s (first defined at line %d)", what, name.Value, where, first.Line)
+		return name.Value
+	}
+	seen[name.Value] = name
+	return name.Value
+}
+
+// isFirstName returns true if a mapping node has a 'name' field seen first in validateName.
+func isFirstName(node *yaml.Node, seen map[string]*yaml.Node) bool {
+	_, name := mappingValue(node, "name")
+	return name != nil && name.Value != "" && seen[name.Value] == name
+}
+
+// seenOutputPath is the output path of a source already validated.
+type seenOutputPath struct {
+	// path is the output path, with the variables of objects of output path templates unexpanded.
+	path string
+
+	// glob is the output path, with '*' for the variables of objects of output path templates.
+	glob string
+
+	// name is the name node of the source.
+	name *yaml.Node
+}
+
+// validateOutputPath validates that the output path of a source is not the output path of another source,
+// and that the output files of the objects of the source cannot be output files of another source, as
+// writing the output files of either source would remove those of the other.
+func (v *validator) validateOutputPath(r Renderer, appName, srcName, where, pathTemplate string, src *yaml.Node, seen *[]seenOutputPath) {
+	outputPath := getRendererOutputFile(r, appName, srcName)
+	glob := outputPath
+	if pathTemplate != "" {
+		vars := map[string]string{"app": appName, "source": srcName, "type": r.Type()}
+		outputPath = expandPathTemplate(pathTemplate, vars)
+		for _, name := range objectTemplateVars {
+			vars[name] = "*"
+		}
+		glob = expandPathTemplate(pathTemplate, vars)
+	}
+	_, name := mappingValue(src, "name")
+	for _, first := range *seen {
+		if first.path == outputPath {
+			v.addf(name, "duplicate output path '%s' of source '%s'%s (first used at line %d)", outputPath, srcName, where, first.name.Line)
+			return
+		}
+		if globsOverlap(first.glob, glob) {
+			v.addf(name, "output path '%s' of source '%s'%s overlaps output path '%s' (first used at line %d)", outputPath, srcName, where, first.path, first.name.Line)
+			return
+		}
+	}
+	*seen = append(*seen, seenOutputPath{path: outputPath, glob: glob, name: name})
+}
+
+// validateEngine validates the 'engine' field of a source, if any.
+func (v *validator) validateEngine(src *yaml.Node) {
+	_, engine := mappingValue(src, "engine")
+	if engine != nil && engine.Value != "" && !contains(validEngines, engine.Value) {
+		v.addf(engine, "unsupported engine '%s' (valid: %s)", engine.Value, strings.Join(validEngines, ", "))
+	}
+}
+
+// unmarshalerType is the type of the yaml.Unmarshaler interface.
+var unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// checkKnownFields checks that all keys of mapping nodes in a node tree are known
+// fields of the Go type the node tree is decoded into.
+func (v *validator) checkKnownFields(node *yaml.Node, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	if t == reflect.TypeOf(yaml.Node{}) || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			v.addf(node, "expected a mapping for %s", typeLabel(t))
+			return
+		}
+		fields, inline := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if field, ok := fields[key.Value]; ok {
+				v.checkKnownFields(value, field.Type)
+				continue
+			}
+			if inline != nil {
+				v.checkKnownFields(value, inline.Elem())
+				continue
+			}
+			v.addf(key, "unknown field '%s' in %s", key.Value, typeLabel(t))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			v.addf(node, "expected a sequence")
+			return
+		}
+		for _, item := range node.Content {
+			v.checkKnownFields(item, t.Elem())
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			v.addf(node, "expected a mapping")
+			return
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			v.checkKnownFields(node.Content[i], t.Elem())
+		}
+	}
+}
+
+// yamlFields returns the fields of a struct type by their YAML keys, including fields
+// of inlined structs, and the type of an inlined map catching all other keys, if any.
+func yamlFields(t reflect.Type) (map[string]reflect.StructField, reflect.Type) {
+	fields := make(map[string]reflect.StructField)
+	var inline reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if contains(strings.Split(opts, ","), "inline") {
+			switch field.Type.Kind() {
+			case reflect.Map:
+				inline = field.Type
+			case reflect.Struct:
+				inlineFields, inlineMap := yamlFields(field.Type)
+				for key, inlineField := range inlineFields {
+					fields[key] = inlineField
+				}
+				if inlineMap != nil {
+					inline = inlineMap
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+	}
+	return fields, inline
+}
+
+// typeLabel returns a label for a config struct type used in validation problems.
+func typeLabel(t reflect.Type) string {
+	return strings.ToLower(t.Name())
+}
+
+// mappingValue returns the key and value nodes of a key in a mapping node, or nils if not found.
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
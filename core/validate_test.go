@@ -0,0 +1,279 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func Test_validateConfigNode(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "should accept valid config",
+			data: `renderfile:
+  schema: v1
+  apps:
+  - name: cert-manager
+    releases:
+    - name: cert-manager
+`,
+			want: []string{},
+		},
+		{
+			name: "should reject empty config",
+			data: ``,
+			want: []string{"renderfile.yaml:1:1: empty Renderfile"},
+		},
+		{
+			name: "should reject unknown fields",
+			data: `renderfile:
+  schema: v1
+  apps:
+  - name: external-dns
+    enabled: true
+    releases:
+    - name: external-dns
+      chrat: bitnami/external-dns
+`,
+			want: []string{
+				"renderfile.yaml:5:5: unknown field 'enabled' in app",
+				"renderfile.yaml:8:7: unknown field 'chrat' in release",
+			},
+		},
+		{
+			name: "should reject unsupported schema",
+			data: `renderfile:
+  schema: v2
+  apps: []
+`,
+			want: []string{"renderfile.yaml:2:11: unsupported schema 'v2' (valid: v1)"},
+		},
+		{
+			name: "should reject unsupported engine",
+			data: `renderfile:
+  schema: v1
+  apps:
+  - name: hello
+    releases:
+    - name: hello
+      chart: charts/hello
+      engine: sdk
+`,
+			want: []string{"renderfile.yaml:8:15: unsupported engine 'sdk' (valid: binary, builtin)"},
+		},
+		{
+			name: "should reject invalid output path templates",
+			data: `renderfile:
+  schema: v1
+  output:
+    pathTemplate: ../{app}/{source}.yaml
+  apps:
+  - name: hello
+    output:
+      pathTemplate: "{app}/{src}.yaml"
+`,
+			want: []string{
+				"renderfile.yaml:4:19: output path template '../{app}/{source}.yaml' is not the relative path of a file in the output directory",
+				"renderfile.yaml:8:21: unknown variable '{src}' in output path template '{app}/{src}.yaml' (valid: {app}, {source}, {type}, {namespace}, {kind}, {name})",
+			},
+		},
+		{
+			name: "should reject output path templates mapping sources to the same file",
+			data: `renderfile:
+  schema: v1
+  output:
+    pathTemplate: "{app}/{namespace}/{source}.yaml"
+  apps:
+  - name: hello
+    bundles:
+    - name: web
+    crds:
+    - name: web
+  - name: world
+    output:
+      pathTemplate: "{app}/{source}.{type}.yaml"
+    bundles:
+    - name: web
+    crds:
+    - name: web
+`,
+			want: []string{"renderfile.yaml:10:13: duplicate output path 'hello/{namespace}/web.yaml' of source 'web' in 'crds' of app 'hello' (first used at line 8)"},
+		},
+		{
+			name: "should reject output path templates mapping objects of sources to the same files",
+			data: `renderfile:
+  schema: v1
+  output:
+    pathTemplate: "{app}/{source}-{kind}-{name}.yaml"
+  apps:
+  - name: hello
+    bundles:
+    - name: web
+    - name: web-api
+  - name: world
+    output:
+      pathTemplate: "{app}/{source}/{kind}-{name}.yaml"
+    bundles:
+    - name: web
+    - name: web-api
+`,
+			want: []string{"renderfile.yaml:9:13: output path 'hello/web-api-{kind}-{name}.yaml' of source 'web-api' in 'bundles' of app 'hello' overlaps output path 'hello/web-{kind}-{name}.yaml' (first used at line 8)"},
+		},
+		{
+			name: "should reject unsupported gitops tools",
+			data: `renderfile:
+  schema: v1
+  gitops:
+    tool: argocd
+  apps:
+  - name: hello
+    gitops:
+      tool: fleet
+`,
+			want: []string{"renderfile.yaml:8:13: unsupported gitops tool 'fleet' (valid: argocd, flux)"},
+		},
+		{
+			name: "should reject invalid JSON Pointers of stripped fields",
+			data: `renderfile:
+  schema: v1
+  apps:
+  - name: hello
+    transform:
+      strip: [/status]
+    releases:
+    - name: hello
+      transform:
+        strip: [metadata/labels]
+`,
+			want: []string{"renderfile.yaml:10:17: invalid JSON Pointer 'metadata/labels': must start with '/'"},
+		},
+		{
+			name: "should reject empty filter rules",
+			data: `renderfile:
+  schema: v1
+  apps:
+  - name: hello
+    releases:
+    - name: hello
+      exclude:
+      - annotations: {helm.sh/hook: "*"}
+      - {}
+`,
+			want: []string{"renderfile.yaml:9:9: empty rule in 'exclude' matches all objects"},
+		},
+		{
+			name: "should reject unknown lint rules",
+			data: `renderfile:
+  schema: v1
+  apps:
+  - name: hello
+    releases:
+    - name: hello
+      suppressLint:
+      - rules: [probes, latest-tag]
+        kind: Job
+`,
+			want: []string{"renderfile.yaml:8:25: unknown lint rule 'latest-tag' (valid: image-tag, resources, privileged, probes, host-path)"},
+		},
+		{
+			name: "should reject ignored differences without valid JSON pointers",
+			data: `renderfile:
+  schema: v1
+  apps:
+  - name: hello
+    releases:
+    - name: hello
+      ignoreDifferences:
+      - kind: Secret
+      - kind: ConfigMap
+        jsonPointers: [/data/token, data]
+`,
+			want: []string{
+				"renderfile.yaml:8:9: missing required field 'jsonPointers' in rule of 'ignoreDifferences'",
+				"renderfile.yaml:10:37: invalid JSON Pointer 'data': must start with '/' in 'ignoreDifferences'",
+			},
+		},
+		{
+			name: "should reject unknown apps and releases and reserved data of environments",
+			data: `renderfile:
+  schema: v1
+  environments:
+  - name: prod
+    data:
+      env: production
+    apps: [web, api]
+    values:
+      web:
+        web: values/prod.yaml
+        db: values/db.yaml
+      api:
+        api: values/api.yaml
+  - name: prod
+  apps:
+  - name: web
+    releases:
+    - name: web
+      chart: charts/web
+    - name: db
+`,
+			want: []string{
+				"renderfile.yaml:6:7: reserved data key 'env' of environment 'prod' is set to the name of the environment",
+				"renderfile.yaml:7:17: unknown app 'api' in 'apps' of environment 'prod'",
+				"renderfile.yaml:11:9: release 'db' of app 'web' in 'values' of environment 'prod' has no chart",
+				"renderfile.yaml:12:7: unknown app 'api' in 'values' of environment 'prod'",
+				"renderfile.yaml:14:11: duplicate environment name 'prod' (first defined at line 4)",
+			},
+		},
+		{
+			name: "should reject missing names",
+			data: `renderfile:
+  schema: v1
+  apps:
+  - disabled: true
+  - name: cert-manager
+    bundles:
+    - sources: []
+`,
+			want: []string{
+				"renderfile.yaml:4:5: missing required field 'name' in app",
+				"renderfile.yaml:7:7: missing required field 'name' in source in 'bundles' of app 'cert-manager'",
+			},
+		},
+		{
+			name: "should reject duplicate names",
+			data: `renderfile:
+  schema: v1
+  apps:
+  - name: cert-manager
+    crds:
+    - name: crds
+    - name: crds
+  - name: cert-manager
+`,
+			want: []string{
+				"renderfile.yaml:7:13: duplicate source name 'crds' in 'crds' of app 'cert-manager' (first defined at line 6)",
+				"renderfile.yaml:8:11: duplicate app name 'cert-manager' (first defined at line 4)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := yaml.Node{}
+			if err := yaml.Unmarshal([]byte(tt.data), &root); err != nil {
+				t.Fatalf("yaml.Unmarshal() error = %v", err)
+			}
+			got := make([]string, 0)
+			for _, err := range validateConfigNode("renderfile.yaml", &root) {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateConfigNode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
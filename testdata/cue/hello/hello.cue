@@ -0,0 +1,19 @@
+package hello
+
+env:      *"dev" | string @tag(env)
+replicas: *1 | int @tag(replicas,type=int)
+
+objects: {
+	configMap: {
+		apiVersion: "v1"
+		kind:       "ConfigMap"
+		metadata: name: "hello-\(env)"
+		data: message: "Hello, World!"
+	}
+	deployment: {
+		apiVersion: "apps/v1"
+		kind:       "Deployment"
+		metadata: name: "hello-\(env)"
+		spec: "replicas": replicas
+	}
+}
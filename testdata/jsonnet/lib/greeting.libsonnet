@@ -0,0 +1,8 @@
+{
+  configMap(name, message):: {
+    apiVersion: 'v1',
+    kind: 'ConfigMap',
+    metadata: { name: name },
+    data: { message: message, port: '8080' },
+  },
+}